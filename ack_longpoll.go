@@ -0,0 +1,191 @@
+package telexy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/renbou/telexy/retry"
+)
+
+// Acked wraps a decoded update with an Ack function the consumer must call
+// exactly once, reporting whether the update was processed successfully.
+// The acking long-poll streamer only advances its getUpdates offset past
+// updates acked with a nil error; a non-nil ack (or none at all by the time
+// the stream is torn down) causes that update, and any following it in the
+// same batch, to be redelivered on the next poll. Ack blocks until the
+// streamer has acted on it, so the offset update (if any) has already
+// happened by the time it returns.
+type Acked[T any] struct {
+	Update T
+	Ack    func(error)
+}
+
+type ackingLongPollStreamer[T any] struct {
+	client  *Client
+	opts    LongPollOptions[T]
+	offset  int64
+	backoff retry.Backoff
+}
+
+// NewAckingLongPoll creates a Streamer like NewLongPoll, except it delivers
+// each update wrapped in Acked and advances its offset only once the
+// consumer acks it successfully, giving at-least-once delivery with
+// processing confirmation (e.g. a database write actually committed)
+// instead of merely a successful channel send. Because it waits for each
+// update's ack before delivering the next, throughput is bounded by how
+// quickly the consumer acks.
+func NewAckingLongPoll[T any](client *Client, opts LongPollOptions[T]) Streamer[Acked[T]] {
+	max := maxBackoff
+	if opts.MaxPollBackoff > 0 {
+		max = opts.MaxPollBackoff
+	}
+	return &ackingLongPollStreamer[T]{
+		client: client,
+		opts:   opts,
+		backoff: retry.Backoff{
+			Min:    minBackoff,
+			Max:    max,
+			Factor: backoffFactor,
+			Jitter: true,
+		},
+	}
+}
+
+func (s *ackingLongPollStreamer[T]) Stream(ctx context.Context) (<-chan Acked[T], <-chan error) {
+	out := make(chan Acked[T])
+	errc := make(chan error, 1)
+
+	go s.run(ctx, out, errc)
+
+	return out, errc
+}
+
+func (s *ackingLongPollStreamer[T]) run(ctx context.Context, out chan<- Acked[T], errc chan<- error) {
+	defer close(out)
+	defer close(errc)
+
+	s.opts.Lifecycle.onStart()
+
+	for {
+		if ctx.Err() != nil {
+			s.opts.Lifecycle.onStop(ctx.Err())
+			return
+		}
+
+		updates, ids, err := s.poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				s.opts.Lifecycle.onStop(ctx.Err())
+				return
+			}
+
+			delay := s.backoff.Next()
+			s.opts.Lifecycle.onRecover(err, delay)
+			select {
+			case errc <- err:
+			default:
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				s.opts.Lifecycle.onStop(ctx.Err())
+				return
+			}
+			continue
+		}
+		s.backoff.Reset()
+
+		if !s.deliver(ctx, out, updates, ids) {
+			s.opts.Lifecycle.onStop(ctx.Err())
+			return
+		}
+	}
+}
+
+// deliver sends each update to out and blocks for its ack before sending
+// the next, advancing s.offset only for updates acked with a nil error. It
+// stops at the first update that isn't acked successfully, leaving it (and
+// anything after it) to be redelivered on the next poll. It returns false
+// if ctx was canceled while delivering.
+//
+// Ack itself blocks until deliver has finished acting on it (in particular,
+// until s.offset has been updated for a nil error), so a caller that has
+// returned from Ack can rely on that update having already happened instead
+// of having to synchronize on s.offset itself.
+func (s *ackingLongPollStreamer[T]) deliver(ctx context.Context, out chan<- Acked[T], updates []T, ids []int64) bool {
+	for i, u := range updates {
+		ackc := make(chan error)
+		done := make(chan struct{})
+		select {
+		case out <- Acked[T]{Update: u, Ack: func(err error) {
+			select {
+			case ackc <- err:
+				<-done
+			case <-ctx.Done():
+			}
+		}}:
+		case <-ctx.Done():
+			return false
+		}
+
+		select {
+		case err := <-ackc:
+			if err != nil {
+				close(done)
+				return true
+			}
+			if ids[i] > s.offset {
+				s.offset = ids[i]
+			}
+			close(done)
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// poll is identical to longPollStreamer.poll except it also returns each
+// update's update_id alongside it, so deliver can advance the offset
+// update-by-update as acks arrive rather than all at once.
+func (s *ackingLongPollStreamer[T]) poll(ctx context.Context) ([]T, []int64, error) {
+	var (
+		updates []T
+		ids     []int64
+	)
+	body := map[string]any{
+		"offset":  s.offset + 1,
+		"timeout": int(s.opts.Timeout.Seconds()),
+	}
+	err := s.client.Do(ctx, "getUpdates", body, func(it *jsoniter.Iterator) error {
+		it.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+			raw := it.SkipAndReturnBytes()
+
+			idIt := json.BorrowIterator(raw)
+			id := readUpdateID(idIt)
+			json.ReturnIterator(idIt)
+
+			valIt := json.BorrowIterator(raw)
+			u, err := s.opts.Decoder(valIt)
+			json.ReturnIterator(valIt)
+			if err != nil {
+				return false
+			}
+
+			updates = append(updates, u)
+			ids = append(ids, id)
+			return true
+		})
+		return it.Error
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("telexy: getUpdates: %w", err)
+	}
+	return updates, ids, nil
+}
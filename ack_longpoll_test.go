@@ -0,0 +1,81 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAckingLongPollRetriesUnackedUpdate(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		switch n {
+		case 1, 2:
+			// Same two updates redelivered until both are acked.
+			w.Write([]byte(`{"ok":true,"result":[
+				{"update_id":1,"message":{"text":"a"}},
+				{"update_id":2,"message":{"text":"b"}}
+			]}`))
+		default:
+			w.Write([]byte(`{"ok":true,"result":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("", "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+
+	lp := NewAckingLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+	lp.(*ackingLongPollStreamer[Update]).backoff.Min = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	// First delivery of update_id 1: nack it, so it isn't consumed and the
+	// offset doesn't advance.
+	first := recvAcked(t, ctx, out)
+	if first.Update.Content.Text != "a" {
+		t.Fatalf("unexpected first update: %+v", first.Update)
+	}
+	first.Ack(context.DeadlineExceeded)
+
+	// Since update_id 1 was nacked, both updates are redelivered from
+	// scratch; ack both successfully this time.
+	second := recvAcked(t, ctx, out)
+	if second.Update.Content.Text != "a" {
+		t.Fatalf("expected update_id 1 to be redelivered, got %+v", second.Update)
+	}
+	second.Ack(nil)
+
+	third := recvAcked(t, ctx, out)
+	if third.Update.Content.Text != "b" {
+		t.Fatalf("unexpected third update: %+v", third.Update)
+	}
+	third.Ack(nil)
+
+	if s := lp.(*ackingLongPollStreamer[Update]); s.offset != 2 {
+		t.Fatalf("expected offset to advance to 2, got %d", s.offset)
+	}
+}
+
+func recvAcked(t *testing.T, ctx context.Context, out <-chan Acked[Update]) Acked[Update] {
+	t.Helper()
+	select {
+	case u := <-out:
+		return u
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for acked update")
+		return Acked[Update]{}
+	}
+}
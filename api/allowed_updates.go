@@ -0,0 +1,13 @@
+package api
+
+// AllowedUpdates converts typed UpdateType values into the string form
+// accepted by getUpdates' allowed_updates parameter, so callers build the
+// list from the UpdateType constants instead of typing out raw strings
+// that could be misspelled.
+func AllowedUpdates(types ...UpdateType) []string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = string(t)
+	}
+	return out
+}
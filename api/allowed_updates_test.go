@@ -0,0 +1,21 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllowedUpdatesConvertsToStrings(t *testing.T) {
+	got := AllowedUpdates(UpdateTypeMessage, UpdateTypeCallbackQuery)
+	want := []string{"message", "callback_query"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestAllowedUpdatesHandlesEmpty(t *testing.T) {
+	got := AllowedUpdates()
+	if len(got) != 0 {
+		t.Fatalf("got %v, want empty", got)
+	}
+}
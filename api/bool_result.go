@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// BoolResult returns a result consumer for Telegram methods whose
+// "result" is simply true on success (setMyCommands, deleteMessage,
+// answerCallbackQuery, and others). It reads and verifies the value is
+// true, returning an error if it's false — which an "ok" response
+// shouldn't produce, but this guards against the unexpected rather than
+// silently treating it as success.
+func BoolResult() func(it *jsoniter.Iterator) error {
+	return func(it *jsoniter.Iterator) error {
+		ok := it.ReadBool()
+		if it.Error != nil {
+			return it.Error
+		}
+		if !ok {
+			return errors.New("telexy: unexpected false result")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+func TestBoolResultAcceptsTrue(t *testing.T) {
+	it := jsoniter.ParseString(jsoniter.ConfigFastest, "true")
+	if err := BoolResult()(it); err != nil {
+		t.Fatalf("BoolResult() returned error for true: %v", err)
+	}
+}
+
+func TestBoolResultRejectsFalse(t *testing.T) {
+	it := jsoniter.ParseString(jsoniter.ConfigFastest, "false")
+	if err := BoolResult()(it); err == nil {
+		t.Fatal("BoolResult() returned nil error for false, want an error")
+	}
+}
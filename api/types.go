@@ -0,0 +1,84 @@
+// Package api holds the wire-level types and decoding helpers shared by the
+// rest of telexy, independent of how updates are transported.
+package api
+
+// UpdateType identifies the kind of update contained in an Update, mirroring
+// the field names accepted by getUpdates' allowed_updates parameter.
+type UpdateType string
+
+const (
+	UpdateTypeMessage       UpdateType = "message"
+	UpdateTypeEditedMessage UpdateType = "edited_message"
+	UpdateTypeChannelPost   UpdateType = "channel_post"
+	UpdateTypeCallbackQuery UpdateType = "callback_query"
+	UpdateTypeInlineQuery   UpdateType = "inline_query"
+	UpdateTypeMyChatMember  UpdateType = "my_chat_member"
+)
+
+// UpdateInfo carries the fields common to every update: the fields needed to
+// route and deduplicate it before any type-specific payload is decoded.
+type UpdateInfo struct {
+	// ID is the update_id. It's int64, not int, so offset tracking and
+	// arithmetic on it stay correct on 32-bit platforms even though
+	// Telegram's update IDs currently fit in int32.
+	ID   int64
+	Type UpdateType
+}
+
+// Chat is a minimal representation of a Telegram chat, covering the fields
+// telexy itself needs. Callers wanting the full shape should decode into
+// their own type. For a private chat, Telegram reports the same chat_id as
+// the user's own id, so a chat lookup also doubles as a way to resolve a
+// user's name and username.
+type Chat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+// MessageEntity describes a semantic entity (mention, command, URL, ...)
+// within a message's text, as reported by Telegram in message.entities.
+type MessageEntity struct {
+	Type   string
+	Offset int
+	Length int
+}
+
+// Content holds the routing-relevant fields extracted from an update's
+// type-specific payload, regardless of which update type it came from.
+type Content struct {
+	Text     string
+	Entities []MessageEntity
+	ChatID   int64
+	UserID   int64
+
+	// MessageID is the message's "message_id" field, zero for update types
+	// that carry no message. Combined with ChatID, it identifies a message
+	// across its edits (message, edited_message, channel_post all share it).
+	MessageID int64
+
+	// Date is the message's Telegram "date" field, Unix seconds, or zero
+	// for update types that carry no message (and thus no date).
+	Date int64
+
+	// CallbackID is the callback_query's "id" field, empty for every other
+	// update type.
+	CallbackID string
+}
+
+// Update is the minimal, routing-friendly representation of a Telegram
+// update, produced by decoders such as telexy.AsUpdate.
+type Update struct {
+	UpdateInfo
+	Content Content
+
+	// ResolvedChat and ResolvedUser carry the result of looking up
+	// Content.ChatID/UserID via getChat, set only by combinators such as
+	// telexy.EnrichWithChatInfo that opt into the extra API calls. Both are
+	// nil on every update produced by a plain decoder.
+	ResolvedChat *Chat
+	ResolvedUser *Chat
+}
@@ -0,0 +1,48 @@
+package telexy
+
+import (
+	"context"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Call is a single request to execute via DoBatch, mirroring Do's
+// parameters.
+type Call struct {
+	Method  string
+	Body    any
+	Consume func(*jsoniter.Iterator) error
+}
+
+// DoBatch executes calls concurrently, each through c.Do (so each gets its
+// own retry/backoff and rate-limit handling), bounded by maxConcurrency
+// (treated as 1 if <= 0 so callers can't accidentally send the whole batch
+// unbounded). It returns one error per call in the same order as calls,
+// with a failed call's error isolated to its own slot rather than
+// aborting the rest of the batch. HTTP/1.1 can't truly pipeline requests,
+// but reusing c's http.Client still gets connection reuse, and overlapping
+// calls up to maxConcurrency improves throughput over issuing them one at
+// a time.
+func (c *Client) DoBatch(ctx context.Context, calls []Call, maxConcurrency int) []error {
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+
+	errs := make([]error, len(calls))
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, call Call) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = c.Do(ctx, call.Method, call.Body, call.Consume)
+		}(i, call)
+	}
+	wg.Wait()
+
+	return errs
+}
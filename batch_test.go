@@ -0,0 +1,76 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+func TestDoBatchReturnsOneResultPerCallInOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bottok/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []Call{
+		{Method: "ok"},
+		{Method: "fail"},
+		{Method: "ok"},
+	}
+	errs := c.DoBatch(context.Background(), calls, 2)
+
+	if len(errs) != 3 {
+		t.Fatalf("got %d results, want 3", len(errs))
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("expected the ok calls to succeed, got %v and %v", errs[0], errs[2])
+	}
+	if errs[1] == nil {
+		t.Fatal("expected the fail call to report an error")
+	}
+}
+
+func TestDoBatchBoundsConcurrencyByMaxConcurrency(t *testing.T) {
+	var inFlight, maxObserved atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxObserved.Load()
+			if cur <= max || maxObserved.CompareAndSwap(max, cur) {
+				break
+			}
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := make([]Call, 10)
+	for i := range calls {
+		calls[i] = Call{Method: "getMe", Consume: func(it *jsoniter.Iterator) error { it.Skip(); return nil }}
+	}
+	c.DoBatch(context.Background(), calls, 3)
+
+	if got := maxObserved.Load(); got > 3 {
+		t.Fatalf("observed %d concurrent calls, want at most 3", got)
+	}
+}
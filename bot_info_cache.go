@@ -0,0 +1,61 @@
+package telexy
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/renbou/telexy/retry"
+)
+
+// BotInfoCache holds the bot's own User, fetched once via GetMe and cached
+// for cheap concurrent reads. Several components (e.g. matching a message
+// against "@botname" in a command) need this without each independently
+// calling GetMe at startup.
+//
+// The zero value is not usable; use StartBotInfoCache. A BotInfoCache is
+// safe for concurrent use.
+type BotInfoCache struct {
+	cached atomic.Pointer[User]
+}
+
+// StartBotInfoCache starts fetching client's own info via GetMe in the
+// background, retrying with backoff until ctx is canceled or the fetch
+// succeeds. It returns immediately rather than blocking startup on the
+// fetch; Get reports ok=false until it completes.
+func StartBotInfoCache(ctx context.Context, client *Client) *BotInfoCache {
+	c := &BotInfoCache{}
+	go c.run(ctx, client)
+	return c
+}
+
+func (c *BotInfoCache) run(ctx context.Context, client *Client) {
+	b := retry.Backoff{Min: minBackoff, Max: maxBackoff, Factor: backoffFactor}
+	for {
+		u, err := client.GetMe(ctx)
+		if err == nil {
+			c.cached.Store(&u)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		timer := time.NewTimer(b.Next())
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Get returns the cached bot User and whether the fetch has completed.
+func (c *BotInfoCache) Get() (User, bool) {
+	u := c.cached.Load()
+	if u == nil {
+		return User{}, false
+	}
+	return *u, true
+}
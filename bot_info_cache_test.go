@@ -0,0 +1,50 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBotInfoCacheFetchesAndCaches(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"ok":false,"error_code":500,"description":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"id":7,"username":"retrybot"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.backoff.Min = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cache := StartBotInfoCache(ctx, c)
+	if _, ok := cache.Get(); ok {
+		t.Fatal("Get reported ok before the fetch could possibly have completed")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if u, ok := cache.Get(); ok {
+			if u.ID != 7 || u.Username != "retrybot" {
+				t.Fatalf("got %+v, want {ID:7 Username:retrybot}", u)
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for BotInfoCache to populate")
+}
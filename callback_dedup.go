@@ -0,0 +1,87 @@
+package telexy
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CallbackDedup deduplicates callback_query updates by ID within a bounded
+// window, dropping repeats Telegram may redeliver (e.g. a user double-
+// tapping a button, or a retry after a slow acknowledgement) before they
+// reach handlers.
+type CallbackDedup struct {
+	size   int
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	order *list.List
+	index map[string]*list.Element
+}
+
+type callbackDedupEntry struct {
+	id   string
+	seen time.Time
+}
+
+// NewCallbackDedup creates a CallbackDedup retaining up to size IDs, each
+// treated as a duplicate for window after it was last seen. size and window
+// must be positive.
+func NewCallbackDedup(size int, window time.Duration) *CallbackDedup {
+	return &CallbackDedup{
+		size:   size,
+		window: window,
+		now:    time.Now,
+		order:  list.New(),
+		index:  make(map[string]*list.Element),
+	}
+}
+
+// Seen reports whether u is a callback_query update whose ID was already
+// seen within window. Non-callback_query updates (empty Content.CallbackID)
+// always report false and aren't tracked.
+func (d *CallbackDedup) Seen(u Update) bool {
+	id := u.Content.CallbackID
+	if id == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.evictExpired(now)
+
+	if el, ok := d.index[id]; ok {
+		el.Value.(*callbackDedupEntry).seen = now
+		d.order.MoveToFront(el)
+		return true
+	}
+
+	el := d.order.PushFront(&callbackDedupEntry{id: id, seen: now})
+	d.index[id] = el
+	for d.order.Len() > d.size {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.index, oldest.Value.(*callbackDedupEntry).id)
+	}
+	return false
+}
+
+// evictExpired drops entries older than window, relying on order always
+// running oldest-to-newest from back to front.
+func (d *CallbackDedup) evictExpired(now time.Time) {
+	for {
+		back := d.order.Back()
+		if back == nil {
+			return
+		}
+		entry := back.Value.(*callbackDedupEntry)
+		if now.Sub(entry.seen) <= d.window {
+			return
+		}
+		d.order.Remove(back)
+		delete(d.index, entry.id)
+	}
+}
@@ -0,0 +1,47 @@
+package telexy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallbackDedupDropsDuplicateWithinWindow(t *testing.T) {
+	d := NewCallbackDedup(10, time.Minute)
+	fixedNow := time.Unix(1000, 0)
+	d.now = func() time.Time { return fixedNow }
+
+	u := Update{Content: Content{CallbackID: "cb1"}}
+
+	if d.Seen(u) {
+		t.Fatal("first sighting reported as duplicate")
+	}
+	if !d.Seen(u) {
+		t.Fatal("second sighting within window not reported as duplicate")
+	}
+
+	fixedNow = fixedNow.Add(2 * time.Minute)
+	if d.Seen(u) {
+		t.Fatal("sighting after window expired reported as duplicate")
+	}
+}
+
+func TestCallbackDedupIgnoresNonCallbackUpdates(t *testing.T) {
+	d := NewCallbackDedup(10, time.Minute)
+	u := Update{Content: Content{Text: "hi"}}
+
+	if d.Seen(u) || d.Seen(u) {
+		t.Fatal("update with no CallbackID should never be reported as a duplicate")
+	}
+}
+
+func TestCallbackDedupEvictsOldestBeyondSize(t *testing.T) {
+	d := NewCallbackDedup(2, time.Hour)
+
+	d.Seen(Update{Content: Content{CallbackID: "a"}})
+	d.Seen(Update{Content: Content{CallbackID: "b"}})
+	d.Seen(Update{Content: Content{CallbackID: "c"}})
+
+	if d.Seen(Update{Content: Content{CallbackID: "a"}}) {
+		t.Fatal("oldest entry should have been evicted once size was exceeded")
+	}
+}
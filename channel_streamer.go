@@ -0,0 +1,41 @@
+package telexy
+
+import "context"
+
+// ChannelStreamer adapts an existing channel to the Streamer interface, for
+// integrations that already have a producer goroutine feeding updates from
+// some custom source (an MQTT broker, a test harness, and so on).
+//
+// The returned Streamer never closes ch; it only stops forwarding from it
+// once ctx is canceled or ch is closed by its owner.
+func ChannelStreamer[T any](ch <-chan T) Streamer[T] {
+	return StreamerFunc[T](func(ctx context.Context) (<-chan T, <-chan error) {
+		out := make(chan T)
+		errc := make(chan error, 1)
+
+		go func() {
+			defer close(out)
+			defer close(errc)
+
+			for {
+				select {
+				case <-ctx.Done():
+					errc <- ctx.Err()
+					return
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						errc <- ctx.Err()
+						return
+					}
+				}
+			}
+		}()
+
+		return out, errc
+	})
+}
@@ -0,0 +1,40 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChannelStreamer(t *testing.T) {
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, _ := ChannelStreamer[int](ch).Stream(ctx)
+
+	for _, want := range []int{1, 2} {
+		select {
+		case got := <-out:
+			if got != want {
+				t.Fatalf("got %d, want %d", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for value")
+		}
+	}
+
+	close(ch)
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected out to close after source channel closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for out to close")
+	}
+}
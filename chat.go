@@ -0,0 +1,26 @@
+package telexy
+
+import (
+	"context"
+
+	"github.com/renbou/telexy/api"
+)
+
+// Chat is a minimal representation of a Telegram chat, covering the fields
+// telexy itself needs. Callers wanting the full shape should decode into
+// their own type. For a private chat, Telegram reports the same chat_id as
+// the user's own id, so GetChat also doubles as a way to resolve a user's
+// name and username.
+//
+// It's an alias for api.Chat: the definition lives in api alongside Update,
+// since Update.ResolvedChat/ResolvedUser reference it and Update itself
+// lives in api (see the MessageEntity doc comment in update.go).
+type Chat = api.Chat
+
+// GetChat returns up to date information about the chat identified by
+// chatID.
+func (c *Client) GetChat(ctx context.Context, chatID int64) (Chat, error) {
+	return DoInto[Chat](ctx, c, "getChat", struct {
+		ChatID int64 `json:"chat_id"`
+	}{chatID})
+}
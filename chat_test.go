@@ -0,0 +1,28 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetChatDecodesChat(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"id":42,"type":"group","title":"Example Group"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chat, err := c.GetChat(context.Background(), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.ID != 42 || chat.Type != "group" || chat.Title != "Example Group" {
+		t.Fatalf("got %+v, want {ID:42 Type:group Title:\"Example Group\"}", chat)
+	}
+}
@@ -0,0 +1,560 @@
+// Package telexy implements a lightweight client and update-processing
+// pipeline for the Telegram Bot API.
+package telexy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/renbou/telexy/retry"
+	"github.com/renbou/telexy/tlxlog"
+)
+
+const defaultEndpoint = "https://api.telegram.org"
+
+const (
+	minBackoff    = 500 * time.Millisecond
+	maxBackoff    = 30 * time.Second
+	backoffFactor = 2.0
+
+	// defaultMaxPooledBufferCapacity bounds how large a *bytes.Buffer may
+	// grow and still be returned to bufPool. A single outsized request
+	// (e.g. a big media group) would otherwise leave a large-capacity
+	// buffer pinned in the pool indefinitely.
+	defaultMaxPooledBufferCapacity = 1 << 20 // 1 MiB
+
+	// bodyDrainLimit bounds how much of an unread response body
+	// drainAndClose will discard before giving up and closing anyway.
+	bodyDrainLimit = 1 << 20 // 1 MiB
+)
+
+var json = jsoniter.ConfigFastest
+
+// maxPooledBufferCapacity is the capacity above which putBuffer discards a
+// buffer instead of returning it to bufPool. It's a package variable rather
+// than a Client field since bufPool itself is shared across all clients;
+// override it with SetMaxPooledBufferCapacity.
+var maxPooledBufferCapacity int64 = defaultMaxPooledBufferCapacity
+
+// SetMaxPooledBufferCapacity configures the capacity threshold above which
+// request-marshalling buffers are discarded rather than reused, capping the
+// memory the pool can retain after a single large request. n must be
+// positive.
+func SetMaxPooledBufferCapacity(n int64) {
+	if n <= 0 {
+		return
+	}
+	atomic.StoreInt64(&maxPooledBufferCapacity, n)
+}
+
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	if int64(buf.Cap()) > atomic.LoadInt64(&maxPooledBufferCapacity) {
+		return
+	}
+	bufPool.Put(buf)
+}
+
+// Client is a low-level Telegram Bot API client responsible for marshalling
+// requests, sending them over HTTP and retrying transient failures.
+type Client struct {
+	hc          *http.Client
+	endpointURL *url.URL
+	backoff     retry.Backoff
+	retryBudget *retry.Budget
+
+	onMigration    func(oldChatID, newChatID int64)
+	onResponseSize func(method string, size int64)
+
+	logger      tlxlog.Logger
+	retryLogger tlxlog.Logger
+
+	dryRun       bool
+	readOnlyMeth map[string]bool
+
+	methodTimeouts map[string]time.Duration
+	requestTimeout time.Duration
+	requestHooks   []RequestHook
+
+	logBodies    bool
+	logBodyLimit int
+
+	testEnv bool
+
+	// self caches the bot's own identity, populated by GetMe and read by
+	// Username.
+	self atomic.Pointer[User]
+
+	mu             sync.RWMutex
+	methodURLCache map[string]string
+}
+
+// RequestHook lets callers observe every attempt Do makes, e.g. to log each
+// API call, inject a trace header (via a closure capturing something
+// outside Do's signature), or measure latency, without wrapping the
+// *http.Client passed to NewClient. Both fields are optional and safe to
+// leave nil. Before runs immediately before the attempt; After runs once
+// it completes, successfully or not, with how long it took. Do calls every
+// configured hook's Before/After around each attempt (including retries),
+// in the order they were configured; a Before/After pair always brackets
+// the same attempt.
+type RequestHook struct {
+	Before func(method string, body any)
+	After  func(method string, err error, duration time.Duration)
+}
+
+// ClientOpts holds optional Client configuration.
+type ClientOpts struct {
+	// RetryBudget, if set, bounds the overall rate of retries Do may issue
+	// across every call made through this Client. Once exhausted, Do fails
+	// fast instead of retrying, which keeps many concurrent callers'
+	// independent retries from compounding into a retry storm during a
+	// widespread outage.
+	RetryBudget *retry.Budget
+
+	// OnMigration, if set, opts into automatically retrying a failed call
+	// once against APIError.MigrateToChatID when the request body supports
+	// redirection (see chatRedirector), and is invoked with the old and
+	// new chat IDs so the caller can persist the change. Left nil, Do
+	// returns the migration error as-is.
+	OnMigration func(oldChatID, newChatID int64)
+
+	// OnResponseSize, if set, is called with the byte size of every
+	// response body Do reads, keyed by method. A ResponseSizeTracker's
+	// Observe method can be used here to build up a running max/mean,
+	// informing whether DefaultDecodeBufferSize is well-sized for a
+	// given bot's traffic.
+	OnResponseSize func(method string, size int64)
+
+	// Logger, if set, receives Client's logs, tagged with component
+	// "client" for request-level events and "retry" for Do's retry loop.
+	// Defaults to tlxlog.Nop.
+	Logger tlxlog.Logger
+
+	// DryRun, if true, makes Do log mutating calls via Logger instead of
+	// sending them to Telegram, returning success without invoking
+	// consume (so the caller gets the zero value for whatever it decodes
+	// into). Calls to a method in ReadOnlyMethods still execute for real,
+	// since a bot can't run at all without reading its own updates. This
+	// is meant for staging runs that exercise real bot logic without
+	// touching real chats.
+	DryRun bool
+
+	// ReadOnlyMethods overrides which Telegram method names Do still
+	// executes for real under DryRun. Defaults to DefaultReadOnlyMethods.
+	// Has no effect unless DryRun is true.
+	ReadOnlyMethods []string
+
+	// StrictTokenValidation, if true, makes NewClient additionally reject
+	// a token that doesn't match the real Bot API token shape
+	// ("\d+:[\w-]+", e.g. "123456789:AAEhBOweik6ad6PsVrSQKTRXrBL9wI")
+	// rather than just rejecting an empty one. It defaults to false since
+	// plenty of existing tests and local tooling use short fake tokens
+	// like "tok" that don't match Telegram's shape but don't need to, to
+	// talk to a test server.
+	StrictTokenValidation bool
+
+	// MethodTimeouts sets a per-method request deadline, applied as a
+	// context.WithTimeout wrapping the context passed to Do, keyed by
+	// the Telegram method name. This lets a single Client serve both a
+	// long-lived getUpdates call and short-lived sends correctly: give
+	// "getUpdates" the long-poll timeout and leave everything else at a
+	// short default, instead of picking one timeout for the underlying
+	// http.Client that's wrong for one of the two. Since
+	// context.WithTimeout never extends an already-shorter deadline the
+	// caller's ctx carries, an explicit deadline from the caller (e.g.
+	// the long poll streamer's own per-call timeout) is still respected.
+	// Methods with no entry are unaffected.
+	MethodTimeouts map[string]time.Duration
+
+	// RequestTimeout, if set, is the default per-attempt deadline applied
+	// to methods with no entry in MethodTimeouts, so a hung connection on
+	// an ordinary send can't block a goroutine forever just because
+	// nobody remembered to list it in MethodTimeouts. Give "getUpdates" a
+	// MethodTimeouts entry covering its long-poll Timeout (or pass an
+	// already-deadlined context to a direct Do call) to exempt it from
+	// this default, since it's expected to sit open far longer than any
+	// other method.
+	RequestTimeout time.Duration
+
+	// RequestHooks, if set, are invoked by Do around every attempt; see
+	// RequestHook.
+	RequestHooks []RequestHook
+
+	// LogBodies, if true, makes Do log the outgoing request body and the
+	// incoming response body of every attempt at debug level (via
+	// tlxlog.Debug), with the bot token and any "token"/"secret"/
+	// "secret_token" JSON field redacted. This is meant for diagnosing a
+	// misbehaving integration, not for routine use: it's opt-in and costs
+	// nothing when left false, since capturing the response body requires
+	// teeing it before it's decoded. Redaction is best-effort, so treat
+	// debug logs as sensitive regardless.
+	LogBodies bool
+
+	// LogBodyLimit bounds how many bytes of each logged body LogBodies
+	// keeps, truncating the rest. Defaults to defaultLogBodyLimit when <= 0.
+	LogBodyLimit int
+
+	// TestEnvironment, if true, targets Telegram's test environment by
+	// inserting "/test" after the token segment of every request URL
+	// ("/bot<token>/test/<method>" instead of "/bot<token>/<method>"),
+	// matching the local Bot API server and api.telegram.org's own /test
+	// mode. File paths returned by a local Bot API server are absolute
+	// local paths regardless of this setting; it only affects the request
+	// URL.
+	TestEnvironment bool
+}
+
+// DefaultReadOnlyMethods lists the Telegram Bot API methods ClientOpts.DryRun
+// still executes for real, since a bot can't function without them: reading
+// updates, its own identity, and file metadata never mutate anything.
+var DefaultReadOnlyMethods = []string{"getUpdates", "getMe", "getFile"}
+
+// tokenShape matches a real Telegram Bot API token: a numeric bot ID,
+// a colon, and the secret part Telegram generates from
+// [A-Za-z0-9_-]. Used only when ClientOpts.StrictTokenValidation is set.
+var tokenShape = regexp.MustCompile(`^\d+:[\w-]+$`)
+
+// errEmptyToken is returned by NewClient when token is empty, almost always
+// a sign of a missing environment variable or config value rather than a
+// deliberate choice.
+var errEmptyToken = errors.New("telexy: empty bot token")
+
+// NewClient creates a Client for the bot identified by token, talking to
+// endpoint (e.g. "https://api.telegram.org", or a local Bot API server's
+// base URL). If endpoint is empty, the default Telegram Bot API endpoint is
+// used. If hc is nil, http.DefaultClient is used. opts may be nil.
+//
+// token must be non-empty; an empty token almost always means a forgotten
+// environment variable, and would otherwise surface later as confusing
+// 404s against "/bot/<method>" instead of a clear error here. If
+// opts.StrictTokenValidation is set, token must also match the real Bot
+// API token shape ("\d+:[\w-]+").
+func NewClient(endpoint, token string, hc *http.Client, opts *ClientOpts) (*Client, error) {
+	if token == "" {
+		return nil, errEmptyToken
+	}
+	if opts != nil && opts.StrictTokenValidation && !tokenShape.MatchString(token) {
+		return nil, fmt.Errorf("telexy: bot token %q doesn't match the expected shape", token)
+	}
+
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	endpointURL, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	testEnv := opts != nil && opts.TestEnvironment
+	endpointURL.Path = botPath(token, testEnv)
+
+	logger := tlxlog.Logger(tlxlog.Nop)
+	if opts != nil && opts.Logger != nil {
+		logger = opts.Logger
+	}
+
+	c := &Client{
+		hc:          hc,
+		endpointURL: endpointURL,
+		backoff: retry.Backoff{
+			Min:    minBackoff,
+			Max:    maxBackoff,
+			Factor: backoffFactor,
+			Jitter: true,
+		},
+		logger:         logger.WithValues("component", tlxlog.ComponentClient),
+		retryLogger:    logger.WithValues("component", tlxlog.ComponentRetry),
+		methodURLCache: make(map[string]string),
+		testEnv:        testEnv,
+	}
+	if opts != nil {
+		c.retryBudget = opts.RetryBudget
+		c.onMigration = opts.OnMigration
+		c.onResponseSize = opts.OnResponseSize
+		c.dryRun = opts.DryRun
+		readOnly := opts.ReadOnlyMethods
+		if readOnly == nil {
+			readOnly = DefaultReadOnlyMethods
+		}
+		c.readOnlyMeth = make(map[string]bool, len(readOnly))
+		for _, m := range readOnly {
+			c.readOnlyMeth[m] = true
+		}
+		c.methodTimeouts = opts.MethodTimeouts
+		c.requestTimeout = opts.RequestTimeout
+		c.requestHooks = opts.RequestHooks
+		c.logBodies = opts.LogBodies
+		c.logBodyLimit = opts.LogBodyLimit
+		if c.logBodyLimit <= 0 {
+			c.logBodyLimit = defaultLogBodyLimit
+		}
+	} else {
+		c.readOnlyMeth = make(map[string]bool)
+	}
+	return c, nil
+}
+
+// SetToken rotates the token used for requests made after it returns,
+// updating the endpoint path and invalidating any cached method URLs.
+// Requests already in flight keep using the token they were built with, so
+// a bot can rotate a compromised token (or pick up a freshly reissued one)
+// without downtime or dropped requests.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endpointURL := *c.endpointURL
+	endpointURL.Path = botPath(token, c.testEnv)
+	c.endpointURL = &endpointURL
+	c.methodURLCache = make(map[string]string)
+}
+
+// botPath builds the URL path segment identifying the bot, and its
+// environment, that every method call is sent to: "/bot<token>", with
+// "/test" appended when testEnv is set.
+func botPath(token string, testEnv bool) string {
+	path := "/bot" + token
+	if testEnv {
+		path += "/test"
+	}
+	return path
+}
+
+// token returns the bot token currently baked into the endpoint path, for
+// redacting it out of debug-logged bodies. It takes c.mu like SetToken does,
+// since SetToken replaces endpointURL under the same lock.
+func (c *Client) token() string {
+	c.mu.RLock()
+	path, testEnv := c.endpointURL.Path, c.testEnv
+	c.mu.RUnlock()
+
+	token := strings.TrimPrefix(path, "/bot")
+	if testEnv {
+		token = strings.TrimSuffix(token, "/test")
+	}
+	return token
+}
+
+func (c *Client) methodURL(method string) string {
+	c.mu.RLock()
+	u, ok := c.methodURLCache[method]
+	c.mu.RUnlock()
+	if ok {
+		return u
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if u, ok := c.methodURLCache[method]; ok {
+		return u
+	}
+	resolved := c.endpointURL.String() + "/" + method
+	c.methodURLCache[method] = resolved
+	return resolved
+}
+
+// errRetryBudgetExhausted is returned by Do when RetryBudget has run out of
+// tokens, so the caller fails fast instead of retrying further.
+var errRetryBudgetExhausted = errors.New("telexy: retry budget exhausted")
+
+// Do marshals body as the JSON request for method, sends it to the Telegram
+// Bot API, and, if the call succeeds, decodes the response's "result" field
+// with consume. Transient failures are retried with exponential backoff
+// until ctx is canceled, a non-retryable error is returned, or the
+// Client's RetryBudget (if configured) is exhausted. If a failure carries
+// APIError.RetryAfter (Telegram's rate-limit hint), the next retry waits
+// exactly that long instead of the backoff's own guess.
+//
+// If OnMigration was configured and a call fails with a migration error
+// (APIError.MigrateToChatID set) for a body implementing chatRedirector, Do
+// redirects body to the new chat ID, invokes OnMigration, and retries
+// immediately. This happens at most once per Do call, so a body that keeps
+// migrating is left to the normal retry loop rather than chased forever.
+func (c *Client) Do(ctx context.Context, method string, body any, consume func(*jsoniter.Iterator) error) error {
+	if c.dryRun && !c.readOnlyMeth[method] {
+		c.logger.Info("dry run: skipping mutating call", "method", method, "body", body)
+		return nil
+	}
+
+	migrated := false
+	var rateLimitDelay time.Duration
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if c.retryBudget != nil && !c.retryBudget.Take() {
+				c.retryLogger.Error("retry budget exhausted", "method", method, "attempt", attempt)
+				return errRetryBudgetExhausted
+			}
+
+			delay := c.backoff.Next()
+			if rateLimitDelay > 0 {
+				// Telegram told us exactly how long to wait; trust that
+				// over our own backoff guess.
+				delay = rateLimitDelay
+				rateLimitDelay = 0
+			}
+			c.retryLogger.Info("retrying request", "method", method, "attempt", attempt, "delay", delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+
+		for _, h := range c.requestHooks {
+			if h.Before != nil {
+				h.Before(method, body)
+			}
+		}
+		start := time.Now()
+		err := c.do(ctx, method, body, consume)
+		elapsed := time.Since(start)
+		for _, h := range c.requestHooks {
+			if h.After != nil {
+				h.After(method, err, elapsed)
+			}
+		}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fastRetry(err) {
+			// Gateway blips resolve quickly; don't make the caller wait out
+			// a backoff escalated by earlier, unrelated failures.
+			c.backoff.Reset()
+		}
+
+		var rateLimitErr *APIError
+		if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+			rateLimitDelay = rateLimitErr.RetryAfter
+		}
+
+		redirected := false
+		if !migrated && c.onMigration != nil {
+			var apiErr *APIError
+			if redirector, ok := body.(chatRedirector); ok && errors.As(err, &apiErr) && apiErr.MigrateToChatID != 0 {
+				migrated = true
+				redirected = true
+				oldChatID := redirector.chatID()
+				redirector.redirectChatID(apiErr.MigrateToChatID)
+				c.onMigration(oldChatID, apiErr.MigrateToChatID)
+				c.backoff.Reset()
+			}
+		}
+
+		if !redirected && IsFatalAPIError(err) {
+			// A 400/401/403/409 can't be fixed by retrying the same
+			// request unchanged, so return it immediately instead of
+			// retrying forever (or until ctx/the retry budget gives up).
+			return err
+		}
+	}
+}
+
+// drainAndClose discards any bytes left unread in resp.Body, up to
+// bodyDrainLimit, before closing it. readResponse always reads resp.Body to
+// completion via io.ReadAll before decoding, so in practice there's nothing
+// left to drain; this exists so a request canceled partway through that
+// read (or any future change to how the body is consumed) still leaves the
+// connection in a state net/http's Transport can consider reusing, instead
+// of always forcing it closed.
+func drainAndClose(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, bodyDrainLimit))
+	resp.Body.Close()
+}
+
+func (c *Client) do(ctx context.Context, method string, body any, consume func(*jsoniter.Iterator) error) error {
+	timeout, ok := c.methodTimeouts[method]
+	if !ok {
+		timeout, ok = c.requestTimeout, c.requestTimeout > 0
+	}
+	if ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if body != nil {
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			return fmt.Errorf("telexy: encode request: %w", err)
+		}
+	}
+
+	var reqLogged []byte
+	if c.logBodies {
+		reqLogged = append([]byte(nil), buf.Bytes()...)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.methodURL(method), buf)
+	if err != nil {
+		return fmt.Errorf("telexy: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.logBodies {
+		c.logRequest(method, reqLogged)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("telexy: do request: %w", err)
+	}
+	defer drainAndClose(resp)
+
+	var counter *countingReader
+	if c.onResponseSize != nil {
+		counter = &countingReader{r: resp.Body}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{counter, resp.Body}
+	}
+
+	var respLogged *bytes.Buffer
+	if c.logBodies {
+		respLogged = getBuffer()
+		defer putBuffer(respLogged)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(resp.Body, respLogged), resp.Body}
+	}
+
+	err = readResponse(method, resp, consume)
+	if counter != nil {
+		c.onResponseSize(method, counter.n)
+	}
+	if c.logBodies {
+		c.logResponse(method, respLogged.Bytes())
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/renbou/telexy/retry"
+)
+
+func TestDoFailsFastWhenRetryBudgetExhausted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"ok":false,"error_code":502,"description":"bad gateway"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("", "tok", srv.Client(), &ClientOpts{RetryBudget: retry.NewBudget(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+	c.backoff.Min = 0
+
+	err = c.Do(context.Background(), "getMe", nil, nil)
+	if err != errRetryBudgetExhausted {
+		t.Fatalf("got %v, want errRetryBudgetExhausted", err)
+	}
+}
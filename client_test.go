@@ -0,0 +1,492 @@
+package telexy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+func TestSetTokenUpdatesMethodURLAndInvalidatesCache(t *testing.T) {
+	c, err := NewClient("https://api.telegram.org", "old-tok", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := c.methodURL("getMe")
+	if !strings.Contains(before, "old-tok") {
+		t.Fatalf("methodURL = %q, want it to contain old-tok", before)
+	}
+
+	c.SetToken("new-tok")
+
+	after := c.methodURL("getMe")
+	if strings.Contains(after, "old-tok") || !strings.Contains(after, "new-tok") {
+		t.Fatalf("methodURL after SetToken = %q, want it to contain new-tok and not old-tok", after)
+	}
+}
+
+func TestSetTokenIsSafeForConcurrentDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "old-tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Do(context.Background(), "sendMessage", nil, nil)
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.SetToken("tok-" + string(rune('a'+i)))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestDoLogsRetriesAsComponentRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"ok":false,"error_code":500,"description":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	logger := newRecordingLogger()
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.backoff.Min = 0
+
+	if err := c.Do(context.Background(), "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries := logger.entriesForComponent("retry"); len(entries) == 0 {
+		t.Fatal("expected at least one log entry tagged component=retry")
+	}
+}
+
+func TestClientRecoversAfterRequestCanceledMidResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		w.Write([]byte(`{"ok":true,"result":`))
+		if flusher != nil {
+			flusher.Flush()
+		}
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canceledCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.do(canceledCtx, "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err == nil {
+		t.Fatal("expected an error from a request whose context is canceled mid-response")
+	}
+
+	// The canceled request must not leave the Client (or its http.Client)
+	// stuck; a later, uncanceled request should still complete normally.
+	ctx, cancel2 := context.WithTimeout(context.Background(), time.Second)
+	defer cancel2()
+	if err := c.Do(ctx, "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatalf("request after a cancellation failed: %v", err)
+	}
+}
+
+func TestDryRunSkipsMutatingCallsButExecutesReadOnlyOnes(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	logger := newRecordingLogger()
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{DryRun: true, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Do(context.Background(), "sendMessage", &SendMessageRequest{ChatID: 1, Text: "hi"}, nil); err != nil {
+		t.Fatalf("dry run call returned error: %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no HTTP call for a mutating method under DryRun, got %d", calls)
+	}
+	if entries := logger.entriesForComponent("client"); len(entries) == 0 {
+		t.Fatal("expected a dry-run log entry tagged component=client")
+	}
+
+	if err := c.Do(context.Background(), "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatalf("read-only call under DryRun returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the read-only method to still execute for real, got %d calls", calls)
+	}
+}
+
+func TestDoWaitsExactlyRetryAfterOnRateLimit(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"slow down","parameters":{"retry_after":1}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A large backoff ceiling would mask whether the real wait came from
+	// RetryAfter or from the backoff's own guess, so set it far below
+	// RetryAfter's 1s.
+	c.backoff.Min = time.Millisecond
+	c.backoff.Max = 2 * time.Millisecond
+
+	start := time.Now()
+	if err := c.Do(context.Background(), "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < 900*time.Millisecond {
+		t.Fatalf("Do returned after %v, want roughly 1s (RetryAfter), not the smaller backoff", elapsed)
+	}
+}
+
+func TestPutBufferDiscardsOversized(t *testing.T) {
+	orig := maxPooledBufferCapacity
+	defer SetMaxPooledBufferCapacity(orig)
+	SetMaxPooledBufferCapacity(16)
+
+	big := getBuffer()
+	big.Grow(1024)
+	putBuffer(big)
+
+	// A discarded buffer means the pool had nothing to hand back, so Get
+	// falls through to New and returns a fresh, zero-capacity buffer.
+	if got := getBuffer().Cap(); got != 0 {
+		t.Fatalf("oversized buffer was retained in the pool: got cap %d", got)
+	}
+}
+
+func TestMethodTimeoutsCancelsSlowMethod(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{
+		MethodTimeouts: map[string]time.Duration{"sendMessage": 20 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.do(context.Background(), "sendMessage", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want a context.DeadlineExceeded-wrapped error", err)
+	}
+}
+
+func TestMethodTimeoutsDoesNotApplyToUnconfiguredMethods(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{
+		MethodTimeouts: map[string]time.Duration{"sendMessage": 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.do(context.Background(), "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatalf("unexpected error for a method with no configured timeout: %v", err)
+	}
+}
+
+func TestMethodTimeoutsRespectsAlreadyShorterCallerDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{
+		MethodTimeouts: map[string]time.Duration{"sendMessage": time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = c.do(ctx, "sendMessage", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the caller's shorter deadline to still apply", err)
+	}
+}
+
+func TestRequestHooksBracketEachAttempt(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"ok":false,"error_code":500,"description":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	var befores, afters []string
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{
+		RequestHooks: []RequestHook{{
+			Before: func(method string, body any) { befores = append(befores, method) },
+			After: func(method string, err error, duration time.Duration) {
+				outcome := "ok"
+				if err != nil {
+					outcome = "err"
+				}
+				afters = append(afters, method+":"+outcome)
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.backoff.Min = 0
+
+	if err := c.Do(context.Background(), "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(befores) != 2 || befores[0] != "getMe" || befores[1] != "getMe" {
+		t.Fatalf("befores = %v, want two getMe entries (one per attempt)", befores)
+	}
+	if len(afters) != 2 || afters[0] != "getMe:err" || afters[1] != "getMe:ok" {
+		t.Fatalf("afters = %v, want [getMe:err getMe:ok]", afters)
+	}
+}
+
+func TestRequestTimeoutAppliesToMethodsWithoutAnOverride(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{RequestTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.do(context.Background(), "sendMessage", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want a context.DeadlineExceeded-wrapped error", err)
+	}
+}
+
+func TestMethodTimeoutsOverridesRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{
+		RequestTimeout: 10 * time.Millisecond,
+		MethodTimeouts: map[string]time.Duration{"getUpdates": time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.do(context.Background(), "getUpdates", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatalf("expected the longer MethodTimeouts entry to win over RequestTimeout, got %v", err)
+	}
+}
+
+func TestLogBodiesRedactsTokenAndSecretFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"secret_token":"sekrit-webhook-value"}}`))
+	}))
+	defer srv.Close()
+
+	logger := newRecordingLogger()
+	c, err := NewClient(srv.URL, "the-bot-token", srv.Client(), &ClientOpts{Logger: logger, LogBodies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type setWebhookBody struct {
+		URL         string `json:"url"`
+		SecretToken string `json:"secret_token"`
+	}
+	body := setWebhookBody{URL: "https://example.com/the-bot-token/hook", SecretToken: "my-secret"}
+	if err := c.do(context.Background(), "setWebhook", body, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	reqKV := logger.kvForMsg("request body")
+	if reqKV == nil {
+		t.Fatal("expected a logged request body entry")
+	}
+	reqBody := kvString(t, reqKV, "body")
+	if strings.Contains(reqBody, "the-bot-token") {
+		t.Fatalf("request body log %q still contains the bot token", reqBody)
+	}
+	if strings.Contains(reqBody, "my-secret") {
+		t.Fatalf("request body log %q still contains the secret field's value", reqBody)
+	}
+
+	respKV := logger.kvForMsg("response body")
+	if respKV == nil {
+		t.Fatal("expected a logged response body entry")
+	}
+	respBody := kvString(t, respKV, "body")
+	if strings.Contains(respBody, "sekrit-webhook-value") {
+		t.Fatalf("response body log %q still contains the secret_token value", respBody)
+	}
+}
+
+func TestLogBodiesIsNoOpWhenDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	logger := newRecordingLogger()
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.do(context.Background(), "getMe", nil, func(it *jsoniter.Iterator) error { it.Skip(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.kvForMsg("request body") != nil || logger.kvForMsg("response body") != nil {
+		t.Fatal("expected no body logging when LogBodies is unset")
+	}
+}
+
+// kvString extracts the string value following key in a recorded kv slice,
+// failing the test if key isn't present.
+func kvString(t *testing.T, kv []any, key string) string {
+	t.Helper()
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] == key {
+			s, ok := kv[i+1].(string)
+			if !ok {
+				t.Fatalf("value for %q is not a string: %v", key, kv[i+1])
+			}
+			return s
+		}
+	}
+	t.Fatalf("key %q not found in %v", key, kv)
+	return ""
+}
+
+func TestTestEnvironmentInsertsTestSegmentAfterToken(t *testing.T) {
+	c, err := NewClient("https://api.telegram.org", "tok", nil, &ClientOpts{TestEnvironment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := c.methodURL("getMe")
+	want := "https://api.telegram.org/bottok/test/getMe"
+	if got != want {
+		t.Fatalf("methodURL = %q, want %q", got, want)
+	}
+}
+
+func TestTestEnvironmentSurvivesSetToken(t *testing.T) {
+	c, err := NewClient("https://api.telegram.org", "old-tok", nil, &ClientOpts{TestEnvironment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetToken("new-tok")
+
+	got := c.methodURL("getMe")
+	want := "https://api.telegram.org/botnew-tok/test/getMe"
+	if got != want {
+		t.Fatalf("methodURL after SetToken = %q, want %q", got, want)
+	}
+}
+
+func TestNewClientRejectsEmptyToken(t *testing.T) {
+	if _, err := NewClient("https://api.telegram.org", "", nil, nil); err == nil {
+		t.Fatal("expected an error for an empty token")
+	}
+}
+
+func TestNewClientAcceptsShortTestTokensByDefault(t *testing.T) {
+	if _, err := NewClient("https://api.telegram.org", "tok", nil, nil); err != nil {
+		t.Fatalf("unexpected error for a non-empty test token: %v", err)
+	}
+}
+
+func TestNewClientStrictTokenValidation(t *testing.T) {
+	cases := []struct {
+		name  string
+		token string
+		want  bool // true if expected to be accepted
+	}{
+		{"real shape", "123456789:AAEhBOweik6ad6PsVrSQKTRXrBL9wI", true},
+		{"short test token", "tok", false},
+		{"missing colon", "123456789AAEhBOweik6ad6PsVrSQKTRXrBL9wI", false},
+		{"non-numeric bot id", "abc:AAEhBOweik6ad6PsVrSQKTRXrBL9wI", false},
+		{"empty secret", "123456789:", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewClient("https://api.telegram.org", tc.token, nil, &ClientOpts{StrictTokenValidation: true})
+			if tc.want && err != nil {
+				t.Fatalf("unexpected error for token %q: %v", tc.token, err)
+			}
+			if !tc.want && err == nil {
+				t.Fatalf("expected an error for token %q", tc.token)
+			}
+		})
+	}
+}
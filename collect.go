@@ -0,0 +1,24 @@
+package telexy
+
+import "context"
+
+// Collect reads up to max values from s (or until s closes, if that comes
+// first), returning them in receipt order. max <= 0 means unbounded: read
+// until s closes. If ctx is canceled before that, Collect returns whatever
+// it collected so far alongside ctx.Err(), rather than blocking forever on
+// a source that stalls.
+func Collect[T any](ctx context.Context, s Stream[T], max int) ([]T, error) {
+	var got []T
+	for max <= 0 || len(got) < max {
+		select {
+		case v, ok := <-s:
+			if !ok {
+				return got, nil
+			}
+			got = append(got, v)
+		case <-ctx.Done():
+			return got, ctx.Err()
+		}
+	}
+	return got, nil
+}
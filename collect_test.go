@@ -0,0 +1,68 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCollectReadsUntilMaxValues(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+
+	got, err := Collect(context.Background(), Stream[int](in), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("got %v, want [1 2]", got)
+	}
+}
+
+func TestCollectUnboundedReadsUntilClose(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	got, err := Collect(context.Background(), Stream[int](in), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("got %v, want [1 2 3]", got)
+	}
+}
+
+func TestCollectStopsEarlyIfStreamClosesBeforeMax(t *testing.T) {
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	got, err := Collect(context.Background(), Stream[int](in), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("got %v, want [1]", got)
+	}
+}
+
+func TestCollectReturnsPartialResultsAndContextErrorOnCancellation(t *testing.T) {
+	in := make(chan int, 1)
+	in <- 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	got, err := Collect(ctx, Stream[int](in), 5)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got err %v, want context.DeadlineExceeded", err)
+	}
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("got %v, want [1] (the value received before the deadline)", got)
+	}
+}
@@ -0,0 +1,158 @@
+package telexy
+
+import jsoniter "github.com/json-iterator/go"
+
+// UpdateDecoder decodes a single raw Telegram update, positioned at by it,
+// into a T.
+type UpdateDecoder[T any] func(it *jsoniter.Iterator) (T, error)
+
+// AsUpdate is an UpdateDecoder that decodes into the routing-friendly
+// Update type, extracting only the fields needed for Mux routing.
+func AsUpdate(it *jsoniter.Iterator) (Update, error) {
+	var u Update
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "update_id":
+			u.ID = it.ReadInt64()
+		case "message", "edited_message", "channel_post":
+			switch field {
+			case "message":
+				u.Type = "message"
+			case "edited_message":
+				u.Type = "edited_message"
+			case "channel_post":
+				u.Type = "channel_post"
+			}
+			u.Content = readMessageContent(it)
+		case "callback_query":
+			u.Type = "callback_query"
+			u.Content = readCallbackQueryContent(it)
+		case "inline_query":
+			u.Type = "inline_query"
+			u.Content = readInlineQueryContent(it)
+		default:
+			it.Skip()
+		}
+		return it.Error == nil
+	})
+	return u, it.Error
+}
+
+func readCallbackQueryContent(it *jsoniter.Iterator) Content {
+	var c Content
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "id":
+			c.CallbackID = it.ReadString()
+		case "data":
+			c.Text = it.ReadString()
+		case "from":
+			it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+				if field == "id" {
+					c.UserID = it.ReadInt64()
+				} else {
+					it.Skip()
+				}
+				return it.Error == nil
+			})
+		case "message":
+			it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+				if field == "chat" {
+					it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+						if field == "id" {
+							c.ChatID = it.ReadInt64()
+						} else {
+							it.Skip()
+						}
+						return it.Error == nil
+					})
+				} else {
+					it.Skip()
+				}
+				return it.Error == nil
+			})
+		default:
+			it.Skip()
+		}
+		return it.Error == nil
+	})
+	return c
+}
+
+func readInlineQueryContent(it *jsoniter.Iterator) Content {
+	var c Content
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "query":
+			c.Text = it.ReadString()
+		case "from":
+			it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+				if field == "id" {
+					c.UserID = it.ReadInt64()
+				} else {
+					it.Skip()
+				}
+				return it.Error == nil
+			})
+		default:
+			it.Skip()
+		}
+		return it.Error == nil
+	})
+	return c
+}
+
+func readMessageContent(it *jsoniter.Iterator) Content {
+	var c Content
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "message_id":
+			c.MessageID = it.ReadInt64()
+		case "text":
+			c.Text = it.ReadString()
+		case "date":
+			c.Date = it.ReadInt64()
+		case "chat":
+			it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+				if field == "id" {
+					c.ChatID = it.ReadInt64()
+				} else {
+					it.Skip()
+				}
+				return it.Error == nil
+			})
+		case "from":
+			it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+				if field == "id" {
+					c.UserID = it.ReadInt64()
+				} else {
+					it.Skip()
+				}
+				return it.Error == nil
+			})
+		case "entities":
+			it.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+				var e MessageEntity
+				it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+					switch field {
+					case "type":
+						e.Type = it.ReadString()
+					case "offset":
+						e.Offset = it.ReadInt()
+					case "length":
+						e.Length = it.ReadInt()
+					default:
+						it.Skip()
+					}
+					return it.Error == nil
+				})
+				c.Entities = append(c.Entities, e)
+				return it.Error == nil
+			})
+		default:
+			it.Skip()
+		}
+		return it.Error == nil
+	})
+	return c
+}
@@ -0,0 +1,120 @@
+package telexy
+
+import "testing"
+
+func TestAsUpdateHandlesUpdateIDBeyondInt32Range(t *testing.T) {
+	data := []byte(`{"update_id":9223372036854775000,"message":{"text":"hi"}}`)
+	it := json.BorrowIterator(data)
+	defer json.ReturnIterator(it)
+
+	u, err := AsUpdate(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 9223372036854775000 {
+		t.Fatalf("ID = %d, want 9223372036854775000", u.ID)
+	}
+}
+
+// TestAsUpdateRoundTripsMaximalInt64IDsAcrossUpdateTypes guards against a
+// regression where jsoniter.ConfigFastest (or a future config change) ends
+// up reading an ID-bearing field as a float, silently losing precision for
+// chat/user IDs near math.MaxInt64. Every ID field decode.go reads already
+// goes through ReadInt64 explicitly rather than a generic/float read, so
+// this should hold as long as that stays true.
+func TestAsUpdateRoundTripsMaximalInt64IDsAcrossUpdateTypes(t *testing.T) {
+	const maxID = 9223372036854775807 // math.MaxInt64
+
+	cases := []struct {
+		name           string
+		json           string
+		wantCallbackID string
+		wantChatID     int64
+		wantUserID     int64
+		wantMessageID  int64
+	}{
+		{
+			name:          "message",
+			json:          `{"update_id":1,"message":{"message_id":9223372036854775807,"chat":{"id":9223372036854775807},"from":{"id":9223372036854775807}}}`,
+			wantMessageID: maxID,
+			wantChatID:    maxID,
+			wantUserID:    maxID,
+		},
+		{
+			name:          "edited_message",
+			json:          `{"update_id":1,"edited_message":{"message_id":9223372036854775807,"chat":{"id":9223372036854775807},"from":{"id":9223372036854775807}}}`,
+			wantMessageID: maxID,
+			wantChatID:    maxID,
+			wantUserID:    maxID,
+		},
+		{
+			name:          "channel_post",
+			json:          `{"update_id":1,"channel_post":{"message_id":9223372036854775807,"chat":{"id":9223372036854775807}}}`,
+			wantMessageID: maxID,
+			wantChatID:    maxID,
+		},
+		{
+			name:           "callback_query",
+			json:           `{"update_id":1,"callback_query":{"id":"cb1","from":{"id":9223372036854775807},"message":{"chat":{"id":9223372036854775807}}}}`,
+			wantCallbackID: "cb1",
+			wantChatID:     maxID,
+			wantUserID:     maxID,
+		},
+		{
+			name:       "inline_query",
+			json:       `{"update_id":1,"inline_query":{"id":"iq1","from":{"id":9223372036854775807},"query":"search text"}}`,
+			wantUserID: maxID,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			it := json.BorrowIterator([]byte(tc.json))
+			defer json.ReturnIterator(it)
+
+			u, err := AsUpdate(it)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Content.CallbackID != tc.wantCallbackID {
+				t.Errorf("CallbackID = %q, want %q", u.Content.CallbackID, tc.wantCallbackID)
+			}
+			if u.Content.ChatID != tc.wantChatID {
+				t.Errorf("ChatID = %d, want %d", u.Content.ChatID, tc.wantChatID)
+			}
+			if u.Content.UserID != tc.wantUserID {
+				t.Errorf("UserID = %d, want %d", u.Content.UserID, tc.wantUserID)
+			}
+			if u.Content.MessageID != tc.wantMessageID {
+				t.Errorf("MessageID = %d, want %d", u.Content.MessageID, tc.wantMessageID)
+			}
+		})
+	}
+}
+
+func TestAsUpdateDecodesInlineQueryText(t *testing.T) {
+	data := []byte(`{"update_id":1,"inline_query":{"id":"iq1","from":{"id":5},"query":"page:2"}}`)
+	it := json.BorrowIterator(data)
+	defer json.ReturnIterator(it)
+
+	u, err := AsUpdate(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Type != "inline_query" {
+		t.Fatalf("Type = %q, want inline_query", u.Type)
+	}
+	if u.Content.Text != "page:2" {
+		t.Fatalf("Text = %q, want page:2", u.Content.Text)
+	}
+}
+
+func TestReadUpdateIDHandlesUpdateIDBeyondInt32Range(t *testing.T) {
+	data := []byte(`{"update_id":9223372036854775000}`)
+	it := json.BorrowIterator(data)
+	defer json.ReturnIterator(it)
+
+	if id := readUpdateID(it); id != 9223372036854775000 {
+		t.Fatalf("readUpdateID = %d, want 9223372036854775000", id)
+	}
+}
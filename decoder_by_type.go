@@ -0,0 +1,60 @@
+package telexy
+
+import (
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/renbou/telexy/api"
+)
+
+// DecoderByType returns an UpdateDecoder that dispatches to a per-type
+// decoder chosen from decoders based on the update's UpdateInfo.Type,
+// falling back to fallback for any type not present in the map. This lets
+// callers mix decoders within a single stream — e.g. a full,
+// allocation-heavy decode for messages alongside a cheap one for
+// everything else — rather than paying every type's decode cost on every
+// update.
+//
+// Like poll's use of readUpdateID, DecoderByType decodes each update
+// twice: once cheaply to classify its type, and once with the chosen
+// decoder.
+func DecoderByType[T any](decoders map[api.UpdateType]UpdateDecoder[T], fallback UpdateDecoder[T]) UpdateDecoder[T] {
+	return func(it *jsoniter.Iterator) (T, error) {
+		raw := it.SkipAndReturnBytes()
+
+		typeIt := json.BorrowIterator(raw)
+		typ := readUpdateType(typeIt)
+		json.ReturnIterator(typeIt)
+
+		decoder := fallback
+		if d, ok := decoders[typ]; ok {
+			decoder = d
+		}
+
+		valIt := json.BorrowIterator(raw)
+		defer json.ReturnIterator(valIt)
+		return decoder(valIt)
+	}
+}
+
+func readUpdateType(it *jsoniter.Iterator) api.UpdateType {
+	var typ api.UpdateType
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "message":
+			typ = api.UpdateTypeMessage
+		case "edited_message":
+			typ = api.UpdateTypeEditedMessage
+		case "channel_post":
+			typ = api.UpdateTypeChannelPost
+		case "callback_query":
+			typ = api.UpdateTypeCallbackQuery
+		case "inline_query":
+			typ = api.UpdateTypeInlineQuery
+		case "my_chat_member":
+			typ = api.UpdateTypeMyChatMember
+		}
+		it.Skip()
+		return it.Error == nil
+	})
+	return typ
+}
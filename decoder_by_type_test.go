@@ -0,0 +1,65 @@
+package telexy
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/renbou/telexy/api"
+)
+
+func TestDecoderByTypeDispatchesByUpdateType(t *testing.T) {
+	decodeLabel := func(label string) UpdateDecoder[string] {
+		return func(it *jsoniter.Iterator) (string, error) {
+			it.Skip()
+			return label, nil
+		}
+	}
+
+	decoder := DecoderByType(map[api.UpdateType]UpdateDecoder[string]{
+		api.UpdateTypeMessage:       decodeLabel("message"),
+		api.UpdateTypeCallbackQuery: decodeLabel("callback_query"),
+	}, decodeLabel("fallback"))
+
+	cases := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"message", `{"update_id":1,"message":{"text":"hi"}}`, "message"},
+		{"callback query", `{"update_id":2,"callback_query":{"id":"x"}}`, "callback_query"},
+		{"unregistered type", `{"update_id":3,"inline_query":{"id":"y"}}`, "fallback"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			it := json.BorrowIterator([]byte(tc.data))
+			defer json.ReturnIterator(it)
+
+			got, err := decoder(it)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecoderByTypeUsesRealAsUpdateDecoder(t *testing.T) {
+	decoder := DecoderByType(map[api.UpdateType]UpdateDecoder[Update]{
+		api.UpdateTypeMessage: AsUpdate,
+	}, AsUpdate)
+
+	it := json.BorrowIterator([]byte(`{"update_id":1,"message":{"text":"hi"}}`))
+	defer json.ReturnIterator(it)
+
+	u, err := decoder(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Content.Text != "hi" {
+		t.Fatalf("Content.Text = %q, want hi", u.Content.Text)
+	}
+}
@@ -0,0 +1,19 @@
+package telexy
+
+import (
+	"context"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// DoInto calls c.Do(ctx, method, body, ...) and decodes the response's
+// "result" field into a T via jsoniter's ReadVal, saving callers from
+// writing a one-off consumer by hand for every typed API call.
+func DoInto[T any](ctx context.Context, c *Client, method string, body any) (T, error) {
+	var v T
+	err := c.Do(ctx, method, body, func(it *jsoniter.Iterator) error {
+		it.ReadVal(&v)
+		return it.Error
+	})
+	return v, err
+}
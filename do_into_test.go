@@ -0,0 +1,49 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDoIntoDecodesResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"id":42,"username":"bot"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := DoInto[User](context.Background(), c, "getMe", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 42 || u.Username != "bot" {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+}
+
+func TestDoIntoPropagatesAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"boom"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{RetryBudget: nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.retryBudget = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	if _, err := DoInto[User](ctx, c, "getMe", nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,90 @@
+package telexy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/renbou/telexy/retry"
+)
+
+// File describes a file available for download, as returned by getFile.
+type File struct {
+	ID   string `json:"file_id"`
+	Path string `json:"file_path"`
+}
+
+// GetFile resolves fileID to its download path.
+func (c *Client) GetFile(ctx context.Context, fileID string) (File, error) {
+	var f File
+	err := c.Do(ctx, "getFile", map[string]string{"file_id": fileID}, func(it *jsoniter.Iterator) error {
+		it.ReadVal(&f)
+		return it.Error
+	})
+	return f, err
+}
+
+func (c *Client) fileURL(path string) string {
+	c.mu.RLock()
+	u := *c.endpointURL
+	c.mu.RUnlock()
+	u.Path = "/file" + u.Path + "/" + path
+	return u.String()
+}
+
+// DownloadFile downloads the file at path (as returned by GetFile) into w,
+// retrying interrupted transfers with backoff. If w also implements
+// io.Seeker, DownloadFile resumes from w's current offset using an HTTP
+// Range request on retry; servers that don't honor the range are handled by
+// restarting the download from the beginning.
+func (c *Client) DownloadFile(ctx context.Context, path string, w io.Writer) error {
+	b := retry.Backoff{Min: minBackoff, Max: maxBackoff, Factor: backoffFactor}
+	return retry.Recover(ctx, &b, func(ctx context.Context) error {
+		return c.downloadAttempt(ctx, path, w)
+	})
+}
+
+func (c *Client) downloadAttempt(ctx context.Context, path string, w io.Writer) error {
+	var resumeFrom int64
+	seeker, canResume := w.(io.Seeker)
+	if canResume {
+		if pos, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			resumeFrom = pos
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.fileURL(path), nil)
+	if err != nil {
+		return fmt.Errorf("telexy: build download request: %w", err)
+	}
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("telexy: download request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Server honored the range; continue writing from resumeFrom.
+	case http.StatusOK:
+		// Server ignored the range and sent the whole file; restart from
+		// the beginning if we'd already written part of it.
+		if resumeFrom > 0 && canResume {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("telexy: reset download destination: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("telexy: download %s: unexpected status %d", path, resp.StatusCode)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
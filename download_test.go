@@ -0,0 +1,60 @@
+package telexy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// seekBuffer lets a bytes.Buffer-backed writer report its write position via
+// io.Seeker, the way a resumable destination file would.
+type seekBuffer struct {
+	bytes.Buffer
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekCurrent {
+		return int64(b.Len()), nil
+	}
+	if offset == 0 && whence == io.SeekStart {
+		b.Reset()
+		return 0, nil
+	}
+	return 0, nil
+}
+
+func TestDownloadFileResumesWithRange(t *testing.T) {
+	const full = "hello, resumable world"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+		if rng == "" {
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, full)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		io.WriteString(w, full[len("hello, "):])
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("", "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+
+	dst := &seekBuffer{}
+	dst.WriteString("hello, ")
+
+	if err := c.DownloadFile(context.Background(), "photos/file.jpg", dst); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if got := dst.String(); got != full {
+		t.Fatalf("got %q, want %q", got, full)
+	}
+}
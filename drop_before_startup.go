@@ -0,0 +1,44 @@
+package telexy
+
+import (
+	"context"
+	"time"
+)
+
+// DropBeforeStartup relays updates from in to the returned Stream, dropping
+// message updates whose Content.Date predates startTime. Unlike DropStale's
+// sliding maxAge, the cutoff here is fixed at startTime for the life of the
+// stream: this matters when Telegram's pending update queue spans process
+// startup, since a sliding window would let per-update staleness grow the
+// longer the stream runs, while "ignore anything older than when I
+// started" should stay fixed. As with DropStale, updates with no date
+// (Content.Date == 0) can't be classified this way and always pass
+// through.
+func DropBeforeStartup(ctx context.Context, in Stream[Update], startTime time.Time) Stream[Update] {
+	cutoff := startTime.Unix()
+	out := make(chan Update)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case u, ok := <-in:
+				if !ok {
+					return
+				}
+				if u.Content.Date != 0 && u.Content.Date < cutoff {
+					continue
+				}
+				select {
+				case out <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
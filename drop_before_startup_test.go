@@ -0,0 +1,37 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDropBeforeStartupFiltersOldMessagesAndPassesOthersThrough(t *testing.T) {
+	startTime := time.Unix(1000, 0)
+
+	in := make(chan Update, 3)
+	in <- Update{Content: Content{Date: 999}}  // predates startup
+	in <- Update{Content: Content{Date: 1000}} // exactly at startup
+	in <- Update{Content: Content{}}           // no date, always passes
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := DropBeforeStartup(ctx, in, startTime)
+
+	var got []int64
+	for u := range out {
+		got = append(got, u.Content.Date)
+	}
+
+	want := []int64{1000, 0}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, d := range want {
+		if got[i] != d {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package telexy
+
+import (
+	"context"
+	"time"
+)
+
+// DropStale relays updates from in to the returned Stream, dropping message
+// updates whose Content.Date is older than maxAge relative to now(). Update
+// types with no date (Content.Date == 0) always pass through. The returned
+// stream closes once ctx is canceled or in closes; inject now for
+// deterministic testing.
+func DropStale(ctx context.Context, in Stream[Update], maxAge time.Duration, now func() time.Time) Stream[Update] {
+	out := make(chan Update)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case u, ok := <-in:
+				if !ok {
+					return
+				}
+				if u.Content.Date != 0 && now().Sub(time.Unix(u.Content.Date, 0)) > maxAge {
+					continue
+				}
+				select {
+				case out <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
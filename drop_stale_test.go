@@ -0,0 +1,40 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/api"
+)
+
+func TestDropStaleFiltersOldMessagesAndPassesOthersThrough(t *testing.T) {
+	fixedNow := time.Unix(1000, 0)
+	now := func() time.Time { return fixedNow }
+
+	in := make(chan Update, 3)
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 1, Type: api.UpdateTypeMessage}, Content: Content{Date: 1000}}
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 2, Type: api.UpdateTypeMessage}, Content: Content{Date: 1000 - 3600}}
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 3, Type: api.UpdateTypeMyChatMember}, Content: Content{}}
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := DropStale(ctx, in, time.Minute, now)
+
+	var got []int64
+	for u := range out {
+		got = append(got, u.ID)
+	}
+
+	want := []int64{1, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, id := range want {
+		if got[i] != id {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package telexy
+
+import (
+	"container/list"
+
+	"github.com/renbou/telexy/api"
+)
+
+// EditEvent pairs an edited_message update with the content telexy last saw
+// for that message.
+type EditEvent struct {
+	Update Update
+
+	// Previous is the message's content before this edit. It's the zero
+	// Content, with PreviousFound false, if the original aged out of the
+	// EditTracker's bounded cache before the edit arrived.
+	Previous Content
+
+	// PreviousFound reports whether Previous was actually recovered from
+	// the cache, since the zero Content is also a valid (if unlikely)
+	// message content and can't be distinguished from "not found" on its
+	// own.
+	PreviousFound bool
+}
+
+type editTrackerKey struct {
+	ChatID    int64
+	MessageID int64
+}
+
+type editTrackerEntry struct {
+	key     editTrackerKey
+	content Content
+}
+
+// EditTracker keeps a bounded cache of recently seen message content, keyed
+// by chat and message ID, so an edited_message update can be enriched with
+// what the message used to say. It's meant for mirroring, logging, or
+// moderation bots that want to surface what changed; bots that don't care
+// about edit history have no reason to use one.
+//
+// Because the cache is bounded, Track is best-effort: editing a message old
+// enough to have been evicted reports PreviousFound=false rather than an
+// error. EditTracker does not itself subscribe to a stream; callers run it
+// inline wherever they already handle Updates.
+//
+// The zero value is not usable; use NewEditTracker. An EditTracker is not
+// safe for concurrent use, matching Mux subscription handlers which are
+// each invoked from a single goroutine.
+type EditTracker struct {
+	size int
+
+	order *list.List
+	index map[editTrackerKey]*list.Element
+}
+
+// NewEditTracker creates an EditTracker retaining content for up to size
+// messages. size must be positive.
+func NewEditTracker(size int) *EditTracker {
+	return &EditTracker{
+		size:  size,
+		order: list.New(),
+		index: make(map[editTrackerKey]*list.Element),
+	}
+}
+
+// Track records u's content for later edits, or, if u is itself an
+// edited_message update, returns an EditEvent pairing u with the message's
+// previous content. ok reports whether u was an edited_message update;
+// every other update type is only remembered, not reported as an edit.
+func (t *EditTracker) Track(u Update) (event EditEvent, ok bool) {
+	key := editTrackerKey{ChatID: u.Content.ChatID, MessageID: u.Content.MessageID}
+
+	if u.Type != api.UpdateTypeEditedMessage {
+		t.rememberOrUpdate(key, u.Content)
+		return EditEvent{}, false
+	}
+
+	event = EditEvent{Update: u}
+	if el, found := t.index[key]; found {
+		entry := el.Value.(*editTrackerEntry)
+		event.Previous = entry.content
+		event.PreviousFound = true
+		entry.content = u.Content
+		t.order.MoveToFront(el)
+	} else {
+		t.rememberOrUpdate(key, u.Content)
+	}
+	return event, true
+}
+
+func (t *EditTracker) rememberOrUpdate(key editTrackerKey, content Content) {
+	if el, ok := t.index[key]; ok {
+		el.Value.(*editTrackerEntry).content = content
+		t.order.MoveToFront(el)
+		return
+	}
+
+	el := t.order.PushFront(&editTrackerEntry{key: key, content: content})
+	t.index[key] = el
+
+	for t.order.Len() > t.size {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.index, oldest.Value.(*editTrackerEntry).key)
+	}
+}
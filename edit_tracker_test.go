@@ -0,0 +1,78 @@
+package telexy
+
+import (
+	"testing"
+
+	"github.com/renbou/telexy/api"
+)
+
+func messageUpdate(typ api.UpdateType, chatID, messageID int64, text string) Update {
+	return Update{
+		UpdateInfo: api.UpdateInfo{Type: typ},
+		Content:    Content{ChatID: chatID, MessageID: messageID, Text: text},
+	}
+}
+
+func TestEditTrackerPairsEditWithPreviousContent(t *testing.T) {
+	tr := NewEditTracker(8)
+
+	if _, ok := tr.Track(messageUpdate(api.UpdateTypeMessage, 1, 100, "hello")); ok {
+		t.Fatal("Track reported ok for a non-edit update")
+	}
+
+	event, ok := tr.Track(messageUpdate(api.UpdateTypeEditedMessage, 1, 100, "hello world"))
+	if !ok {
+		t.Fatal("Track reported ok=false for an edited_message update")
+	}
+	if !event.PreviousFound {
+		t.Fatal("PreviousFound = false, want true")
+	}
+	if event.Previous.Text != "hello" {
+		t.Fatalf("Previous.Text = %q, want %q", event.Previous.Text, "hello")
+	}
+	if event.Update.Content.Text != "hello world" {
+		t.Fatalf("Update.Content.Text = %q, want %q", event.Update.Content.Text, "hello world")
+	}
+}
+
+func TestEditTrackerReportsNotFoundWhenOriginalWasEvicted(t *testing.T) {
+	tr := NewEditTracker(1)
+
+	tr.Track(messageUpdate(api.UpdateTypeMessage, 1, 100, "first"))
+	tr.Track(messageUpdate(api.UpdateTypeMessage, 1, 200, "second")) // evicts message 100
+
+	event, ok := tr.Track(messageUpdate(api.UpdateTypeEditedMessage, 1, 100, "edited"))
+	if !ok {
+		t.Fatal("Track reported ok=false for an edited_message update")
+	}
+	if event.PreviousFound {
+		t.Fatal("PreviousFound = true, want false after eviction")
+	}
+}
+
+func TestEditTrackerChainsSubsequentEdits(t *testing.T) {
+	tr := NewEditTracker(8)
+
+	tr.Track(messageUpdate(api.UpdateTypeMessage, 1, 100, "v1"))
+	tr.Track(messageUpdate(api.UpdateTypeEditedMessage, 1, 100, "v2"))
+
+	event, ok := tr.Track(messageUpdate(api.UpdateTypeEditedMessage, 1, 100, "v3"))
+	if !ok {
+		t.Fatal("Track reported ok=false for an edited_message update")
+	}
+	if event.Previous.Text != "v2" {
+		t.Fatalf("Previous.Text = %q, want %q", event.Previous.Text, "v2")
+	}
+}
+
+func TestEditTrackerDistinguishesMessagesByChatID(t *testing.T) {
+	tr := NewEditTracker(8)
+
+	tr.Track(messageUpdate(api.UpdateTypeMessage, 1, 100, "chat one"))
+	tr.Track(messageUpdate(api.UpdateTypeMessage, 2, 100, "chat two"))
+
+	event, _ := tr.Track(messageUpdate(api.UpdateTypeEditedMessage, 2, 100, "chat two edited"))
+	if event.Previous.Text != "chat two" {
+		t.Fatalf("Previous.Text = %q, want %q", event.Previous.Text, "chat two")
+	}
+}
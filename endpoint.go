@@ -0,0 +1,31 @@
+package telexy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// normalizeEndpoint parses and validates a Bot API endpoint, stripping
+// trailing slashes and rejecting the subtly broken inputs that would
+// otherwise produce confusing 401/404 responses: missing http(s) schemes
+// and endpoints that already embed a "bot" path segment (which would make
+// NewClient append a second one).
+func normalizeEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("telexy: parse endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("telexy: endpoint %q must have an http or https scheme", endpoint)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("telexy: endpoint %q is missing a host", endpoint)
+	}
+	for _, seg := range strings.Split(strings.Trim(u.Path, "/"), "/") {
+		if strings.HasPrefix(seg, "bot") {
+			return nil, fmt.Errorf("telexy: endpoint %q must not already contain a bot path segment", endpoint)
+		}
+	}
+	return u, nil
+}
@@ -0,0 +1,37 @@
+package telexy
+
+import "testing"
+
+func TestNormalizeEndpoint(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint string
+		wantErr  bool
+		wantPath string
+	}{
+		{"valid", "https://api.telegram.org", false, ""},
+		{"trailing slash", "https://api.telegram.org/", false, ""},
+		{"missing scheme", "api.telegram.org", true, ""},
+		{"ftp scheme", "ftp://api.telegram.org", true, ""},
+		{"embedded bot segment", "https://api.telegram.org/bot123:abc", true, ""},
+		{"empty", "", true, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := normalizeEndpoint(tc.endpoint)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tc.endpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if u.Path != tc.wantPath {
+				t.Fatalf("got path %q, want %q", u.Path, tc.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package telexy
+
+import (
+	"container/list"
+	"context"
+	"time"
+)
+
+// EnrichFields selects which of Update.ResolvedChat/ResolvedUser
+// EnrichWithChatInfo populates. Resolving a field it's given false for is
+// skipped entirely, avoiding the extra getChat call for bots that only
+// care about one of the two.
+type EnrichFields struct {
+	Chat bool
+	User bool
+}
+
+// EnrichWithChatInfo relays values from in to the returned Stream,
+// attaching ResolvedChat and/or ResolvedUser (per fields) by calling
+// client.GetChat for Content.ChatID and Content.UserID respectively.
+// Telegram's getChat also resolves a private chat's name/username, so the
+// same call doubles as user lookup when ChatID and UserID refer to the
+// same private chat.
+//
+// Results are cached per chat ID in an LRU bounded by maxEntries and
+// expired after ttl, so a long-running stream doesn't grow this
+// combinator's memory without bound or keep re-fetching a chat's details
+// on every update. A lookup failure (including a zero ID, which has
+// nothing to resolve) simply leaves the corresponding field nil; the
+// update is still delivered rather than dropped. Inject now for
+// deterministic testing. The returned stream closes once ctx is canceled
+// or in closes.
+func EnrichWithChatInfo(ctx context.Context, in Stream[Update], client *Client, fields EnrichFields, maxEntries int, ttl time.Duration, now func() time.Time) Stream[Update] {
+	out := make(chan Update)
+	cache := &chatInfoCache{ttl: ttl, max: maxEntries, now: now, order: list.New(), index: make(map[int64]*list.Element)}
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case u, ok := <-in:
+				if !ok {
+					return
+				}
+				if fields.Chat {
+					u.ResolvedChat = cache.resolve(ctx, client, u.Content.ChatID)
+				}
+				if fields.User {
+					if u.Content.UserID == u.Content.ChatID {
+						u.ResolvedUser = u.ResolvedChat
+					} else {
+						u.ResolvedUser = cache.resolve(ctx, client, u.Content.UserID)
+					}
+				}
+				select {
+				case out <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// chatInfoCache is an LRU, TTL'd cache of Chat lookups keyed by chat ID.
+// It's only ever touched from EnrichWithChatInfo's single driving
+// goroutine, so it needs no mutex.
+type chatInfoCache struct {
+	ttl time.Duration
+	max int
+	now func() time.Time
+
+	order *list.List
+	index map[int64]*list.Element
+}
+
+type chatInfoEntry struct {
+	chatID  int64
+	chat    Chat
+	fetched time.Time
+}
+
+// resolve returns the cached or freshly-fetched Chat for chatID, or nil if
+// chatID is zero or the lookup fails.
+func (c *chatInfoCache) resolve(ctx context.Context, client *Client, chatID int64) *Chat {
+	if chatID == 0 {
+		return nil
+	}
+
+	now := c.now()
+	if el, ok := c.index[chatID]; ok {
+		c.order.MoveToFront(el)
+		entry := el.Value.(*chatInfoEntry)
+		if now.Sub(entry.fetched) < c.ttl {
+			chat := entry.chat
+			return &chat
+		}
+	}
+
+	chat, err := client.GetChat(ctx, chatID)
+	if err != nil {
+		return nil
+	}
+
+	if el, ok := c.index[chatID]; ok {
+		entry := el.Value.(*chatInfoEntry)
+		entry.chat, entry.fetched = chat, now
+		c.order.MoveToFront(el)
+	} else {
+		el := c.order.PushFront(&chatInfoEntry{chatID: chatID, chat: chat, fetched: now})
+		c.index[chatID] = el
+		for c.order.Len() > c.max {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.index, oldest.Value.(*chatInfoEntry).chatID)
+		}
+	}
+
+	return &chat
+}
@@ -0,0 +1,110 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnrichWithChatInfoAttachesResolvedChat(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"ok":true,"result":{"id":1,"type":"group","title":"Example Group"}}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := EnrichWithChatInfo(ctx, Stream[Update](in), client, EnrichFields{Chat: true}, 16, time.Minute, time.Now)
+
+	in <- Update{Content: Content{ChatID: 1}}
+	got := <-out
+	if got.ResolvedChat == nil || got.ResolvedChat.Title != "Example Group" {
+		t.Fatalf("ResolvedChat = %+v, want a resolved chat titled Example Group", got.ResolvedChat)
+	}
+
+	// A second update for the same chat should hit the cache, not issue
+	// another getChat call.
+	in <- Update{Content: Content{ChatID: 1}}
+	<-out
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("getChat called %d times, want 1 (second lookup should be cached)", got)
+	}
+
+	close(in)
+	if _, ok := <-out; ok {
+		t.Fatal("expected out to close once in closes")
+	}
+}
+
+func TestEnrichWithChatInfoDeliversUnenrichedOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"chat not found"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := EnrichWithChatInfo(ctx, Stream[Update](in), client, EnrichFields{Chat: true}, 16, time.Minute, time.Now)
+
+	in <- Update{Content: Content{ChatID: 1}}
+	got := <-out
+	if got.ResolvedChat != nil {
+		t.Fatalf("ResolvedChat = %+v, want nil after a failed lookup", got.ResolvedChat)
+	}
+
+	close(in)
+}
+
+func TestEnrichWithChatInfoSkipsUnrequestedFields(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"ok":true,"result":{"id":1,"type":"private","first_name":"Ada"}}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := EnrichWithChatInfo(ctx, Stream[Update](in), client, EnrichFields{User: true}, 16, time.Minute, time.Now)
+
+	in <- Update{Content: Content{ChatID: 1, UserID: 1}}
+	got := <-out
+	if got.ResolvedChat != nil {
+		t.Fatal("expected ResolvedChat to stay nil when only User is requested")
+	}
+	if got.ResolvedUser == nil || got.ResolvedUser.FirstName != "Ada" {
+		t.Fatalf("ResolvedUser = %+v, want a resolved user named Ada", got.ResolvedUser)
+	}
+	if calls != 1 {
+		t.Fatalf("getChat called %d times, want 1", calls)
+	}
+
+	close(in)
+}
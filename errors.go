@@ -0,0 +1,222 @@
+package telexy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIError represents an error response returned by the Telegram Bot API,
+// i.e. a response with "ok": false.
+type APIError struct {
+	Method      string
+	Code        int
+	Description string
+
+	// MigrateToChatID is set when the error's "parameters" carry
+	// migrate_to_chat_id, meaning the target group has migrated to a
+	// supergroup with this new chat ID. Zero if not present.
+	MigrateToChatID int64
+
+	// RetryAfter is set when the error's "parameters" carry retry_after,
+	// meaning Telegram is rate-limiting this bot (typically a 429) and
+	// reports exactly how long to wait before trying again. Zero if not
+	// present.
+	RetryAfter time.Duration
+}
+
+// IsRateLimited reports whether e was caused by Telegram rate-limiting the
+// bot, in which case RetryAfter holds how long to wait before retrying.
+func (e *APIError) IsRateLimited() bool {
+	return e.RetryAfter > 0
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telexy: %s: %d %s", e.Method, e.Code, e.Description)
+}
+
+// IsUserBlocked reports whether e was caused by the target user having
+// blocked the bot. Broadcast and notification bots hitting this should
+// prune the user from their subscriber list rather than retrying.
+func (e *APIError) IsUserBlocked() bool {
+	return e.Code == http.StatusForbidden && strings.Contains(e.Description, "bot was blocked by the user")
+}
+
+// IsUserDeactivated reports whether e was caused by the target user's
+// Telegram account having been deleted, which like IsUserBlocked means the
+// user is unreachable for good.
+func (e *APIError) IsUserDeactivated() bool {
+	return e.Code == http.StatusForbidden && strings.Contains(e.Description, "user is deactivated")
+}
+
+// IsChatNotFound reports whether e was caused by the target chat no
+// longer existing. For a direct-message chat this usually means the user
+// deleted their account or never started a conversation with the bot.
+func (e *APIError) IsChatNotFound() bool {
+	return e.Code == http.StatusBadRequest && strings.Contains(e.Description, "chat not found")
+}
+
+// IsUnreachableUser reports whether err is an APIError in any of the
+// categories that mean the bot can no longer message the target user:
+// blocked, deactivated, or chat not found. Callers sending to a list of
+// users can use this to decide when to prune a subscriber rather than
+// treating the send as a generic, possibly-transient failure.
+func IsUnreachableUser(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.IsUserBlocked() || apiErr.IsUserDeactivated() || apiErr.IsChatNotFound()
+}
+
+// IsFatalAPIError reports whether err is an APIError whose code means no
+// amount of retrying will help: a malformed request (400), an invalid or
+// revoked token (401), the bot lacking permission for the action (403), or
+// another getUpdates call already holding the long-poll connection (409).
+// Callers driving their own retry loop can use this to stop immediately
+// instead of retrying a call that can never succeed.
+func IsFatalAPIError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict:
+		return true
+	default:
+		return false
+	}
+}
+
+// ErrKind classifies an APIError into one of a small set of categories
+// callers commonly branch on, so they don't have to scatter their own
+// string matches against Description the way IsUserBlocked et al. used to
+// be the only alternative for. Telegram gives no stable machine-readable
+// error codes, so Kind still pattern-matches Code and Description under
+// the hood; it just centralizes that matching in one place.
+type ErrKind int
+
+const (
+	// ErrKindUnknown covers every APIError that doesn't match one of the
+	// categories below. Treat it as "a generic API error", not as "this
+	// can't happen" — new Telegram error shapes land here until Kind is
+	// taught to recognize them.
+	ErrKindUnknown ErrKind = iota
+
+	// ErrKindRateLimit means Telegram is rate-limiting the bot (see
+	// APIError.IsRateLimited and RetryAfter).
+	ErrKindRateLimit
+
+	// ErrKindBotBlocked means the target user has blocked the bot (see
+	// APIError.IsUserBlocked).
+	ErrKindBotBlocked
+
+	// ErrKindUserDeactivated means the target user's account no longer
+	// exists (see APIError.IsUserDeactivated).
+	ErrKindUserDeactivated
+
+	// ErrKindChatNotFound means the target chat no longer exists (see
+	// APIError.IsChatNotFound).
+	ErrKindChatNotFound
+
+	// ErrKindMessageNotModified means an editMessage* call's new content
+	// was identical to the existing message, which Telegram rejects as
+	// an error rather than a no-op success. Callers doing idempotent
+	// edits (e.g. refreshing a live-updating message on a timer) can
+	// treat this one specifically as "already up to date" rather than a
+	// real failure.
+	ErrKindMessageNotModified
+
+	// ErrKindBadRequest means the request itself was malformed (400):
+	// retrying it unchanged will never succeed.
+	ErrKindBadRequest
+
+	// ErrKindUnauthorized means the bot token is invalid or revoked (401).
+	ErrKindUnauthorized
+
+	// ErrKindPermissionDenied means the bot lacks permission for the
+	// requested action (403) for a reason other than IsUserBlocked.
+	ErrKindPermissionDenied
+
+	// ErrKindConflict means another getUpdates call already holds the
+	// long-poll connection, or a similar exclusivity conflict (409).
+	ErrKindConflict
+
+	// ErrKindGatewayError means Telegram's own infrastructure returned a
+	// transient 502/504 (see fastRetry); retrying promptly usually
+	// succeeds.
+	ErrKindGatewayError
+)
+
+func (k ErrKind) String() string {
+	switch k {
+	case ErrKindRateLimit:
+		return "rate_limit"
+	case ErrKindBotBlocked:
+		return "bot_blocked"
+	case ErrKindUserDeactivated:
+		return "user_deactivated"
+	case ErrKindChatNotFound:
+		return "chat_not_found"
+	case ErrKindMessageNotModified:
+		return "message_not_modified"
+	case ErrKindBadRequest:
+		return "bad_request"
+	case ErrKindUnauthorized:
+		return "unauthorized"
+	case ErrKindPermissionDenied:
+		return "permission_denied"
+	case ErrKindConflict:
+		return "conflict"
+	case ErrKindGatewayError:
+		return "gateway_error"
+	default:
+		return "unknown"
+	}
+}
+
+// Kind classifies e into an ErrKind, checked in roughly most-specific to
+// least-specific order: the named categories (rate limiting, blocked
+// users, ...) before the generic HTTP-status-code buckets that several of
+// them happen to share with less specific errors.
+func (e *APIError) Kind() ErrKind {
+	switch {
+	case e.IsRateLimited():
+		return ErrKindRateLimit
+	case e.IsUserBlocked():
+		return ErrKindBotBlocked
+	case e.IsUserDeactivated():
+		return ErrKindUserDeactivated
+	case e.IsChatNotFound():
+		return ErrKindChatNotFound
+	case e.Code == http.StatusBadRequest && strings.Contains(e.Description, "message is not modified"):
+		return ErrKindMessageNotModified
+	case e.Code == http.StatusBadGateway, e.Code == http.StatusGatewayTimeout:
+		return ErrKindGatewayError
+	case e.Code == http.StatusBadRequest:
+		return ErrKindBadRequest
+	case e.Code == http.StatusUnauthorized:
+		return ErrKindUnauthorized
+	case e.Code == http.StatusForbidden:
+		return ErrKindPermissionDenied
+	case e.Code == http.StatusConflict:
+		return ErrKindConflict
+	default:
+		return ErrKindUnknown
+	}
+}
+
+// fastRetry reports whether err is the kind of transient error that should
+// be retried at the backoff's minimum delay rather than continuing an
+// escalated backoff sequence. Telegram's 502/504 gateway errors are
+// typically load-related blips that resolve within seconds, so honoring a
+// delay escalated by earlier, unrelated failures only slows recovery.
+func fastRetry(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusBadGateway || apiErr.Code == http.StatusGatewayTimeout
+	}
+	return false
+}
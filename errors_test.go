@@ -0,0 +1,191 @@
+package telexy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFastRetry(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"bad gateway", &APIError{Code: http.StatusBadGateway}, true},
+		{"gateway timeout", &APIError{Code: http.StatusGatewayTimeout}, true},
+		{"too many requests", &APIError{Code: http.StatusTooManyRequests}, false},
+		{"not an api error", errors.New("boom"), false},
+		{"wrapped api error", fmtErrorf(&APIError{Code: http.StatusBadGateway}), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fastRetry(tc.err); got != tc.want {
+				t.Errorf("fastRetry(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorIsUserBlocked(t *testing.T) {
+	cases := []struct {
+		name            string
+		err             *APIError
+		blocked         bool
+		deactivated     bool
+		chatNotFound    bool
+		unreachableUser bool
+	}{
+		{
+			name:            "blocked",
+			err:             &APIError{Code: http.StatusForbidden, Description: "Forbidden: bot was blocked by the user"},
+			blocked:         true,
+			unreachableUser: true,
+		},
+		{
+			name:            "deactivated",
+			err:             &APIError{Code: http.StatusForbidden, Description: "Forbidden: user is deactivated"},
+			deactivated:     true,
+			unreachableUser: true,
+		},
+		{
+			name:            "chat not found",
+			err:             &APIError{Code: http.StatusBadRequest, Description: "Bad Request: chat not found"},
+			chatNotFound:    true,
+			unreachableUser: true,
+		},
+		{
+			name: "unrelated forbidden",
+			err:  &APIError{Code: http.StatusForbidden, Description: "Forbidden: bot can't send messages to bots"},
+		},
+		{
+			name: "unrelated bad request",
+			err:  &APIError{Code: http.StatusBadRequest, Description: "Bad Request: message text is empty"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.err.IsUserBlocked(); got != tc.blocked {
+				t.Errorf("IsUserBlocked() = %v, want %v", got, tc.blocked)
+			}
+			if got := tc.err.IsUserDeactivated(); got != tc.deactivated {
+				t.Errorf("IsUserDeactivated() = %v, want %v", got, tc.deactivated)
+			}
+			if got := tc.err.IsChatNotFound(); got != tc.chatNotFound {
+				t.Errorf("IsChatNotFound() = %v, want %v", got, tc.chatNotFound)
+			}
+			if got := IsUnreachableUser(tc.err); got != tc.unreachableUser {
+				t.Errorf("IsUnreachableUser() = %v, want %v", got, tc.unreachableUser)
+			}
+		})
+	}
+
+	if IsUnreachableUser(errors.New("boom")) {
+		t.Error("IsUnreachableUser() = true for a non-APIError")
+	}
+}
+
+func TestReadResponseSurfacesUserBlockedFromCannedResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(http.StatusForbidden)
+	rec.WriteString(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)
+
+	err := readResponse("sendMessage", rec.Result(), nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if !apiErr.IsUserBlocked() {
+		t.Fatalf("expected IsUserBlocked, got %+v", apiErr)
+	}
+	if !IsUnreachableUser(err) {
+		t.Fatal("expected IsUnreachableUser to report true")
+	}
+}
+
+func TestReadResponseParsesRetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(http.StatusTooManyRequests)
+	rec.WriteString(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 3","parameters":{"retry_after":3}}`)
+
+	err := readResponse("sendMessage", rec.Result(), nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if !apiErr.IsRateLimited() {
+		t.Fatalf("expected IsRateLimited, got %+v", apiErr)
+	}
+	if apiErr.RetryAfter != 3*time.Second {
+		t.Fatalf("RetryAfter = %v, want 3s", apiErr.RetryAfter)
+	}
+}
+
+func TestIsFatalAPIError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"bad request", &APIError{Code: http.StatusBadRequest}, true},
+		{"unauthorized", &APIError{Code: http.StatusUnauthorized}, true},
+		{"forbidden", &APIError{Code: http.StatusForbidden}, true},
+		{"conflict", &APIError{Code: http.StatusConflict}, true},
+		{"too many requests", &APIError{Code: http.StatusTooManyRequests}, false},
+		{"bad gateway", &APIError{Code: http.StatusBadGateway}, false},
+		{"not an api error", errors.New("boom"), false},
+		{"wrapped fatal api error", fmtErrorf(&APIError{Code: http.StatusUnauthorized}), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsFatalAPIError(tc.err); got != tc.want {
+				t.Errorf("IsFatalAPIError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorKind(t *testing.T) {
+	cases := []struct {
+		name string
+		err  *APIError
+		want ErrKind
+	}{
+		{"rate limit", &APIError{Code: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}, ErrKindRateLimit},
+		{"bot blocked", &APIError{Code: http.StatusForbidden, Description: "Forbidden: bot was blocked by the user"}, ErrKindBotBlocked},
+		{"user deactivated", &APIError{Code: http.StatusForbidden, Description: "Forbidden: user is deactivated"}, ErrKindUserDeactivated},
+		{"chat not found", &APIError{Code: http.StatusBadRequest, Description: "Bad Request: chat not found"}, ErrKindChatNotFound},
+		{"message not modified", &APIError{Code: http.StatusBadRequest, Description: "Bad Request: message is not modified"}, ErrKindMessageNotModified},
+		{"bad gateway", &APIError{Code: http.StatusBadGateway}, ErrKindGatewayError},
+		{"gateway timeout", &APIError{Code: http.StatusGatewayTimeout}, ErrKindGatewayError},
+		{"bad request", &APIError{Code: http.StatusBadRequest, Description: "Bad Request: message text is empty"}, ErrKindBadRequest},
+		{"unauthorized", &APIError{Code: http.StatusUnauthorized}, ErrKindUnauthorized},
+		{"permission denied", &APIError{Code: http.StatusForbidden, Description: "Forbidden: bot can't send messages to bots"}, ErrKindPermissionDenied},
+		{"conflict", &APIError{Code: http.StatusConflict}, ErrKindConflict},
+		{"unknown", &APIError{Code: http.StatusInternalServerError}, ErrKindUnknown},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.err.Kind(); got != tc.want {
+				t.Errorf("Kind() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func fmtErrorf(err error) error {
+	return &wrappedError{err}
+}
+
+type wrappedError struct{ err error }
+
+func (w *wrappedError) Error() string { return "wrapped: " + w.err.Error() }
+func (w *wrappedError) Unwrap() error { return w.err }
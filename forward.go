@@ -0,0 +1,56 @@
+package telexy
+
+import (
+	"context"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// forwardMessageRequest is the request body for forwardMessage and
+// copyMessage, which share the same parameters.
+type forwardMessageRequest struct {
+	ChatID     int64 `json:"chat_id"`
+	FromChatID int64 `json:"from_chat_id"`
+	MessageID  int   `json:"message_id"`
+
+	SendOptions
+}
+
+// ForwardMessage forwards the message identified by messageID from
+// fromChatID into toChatID.
+func (c *Client) ForwardMessage(ctx context.Context, toChatID, fromChatID int64, messageID int, opts SendOptions) (Message, error) {
+	var msg Message
+	err := c.Do(ctx, "forwardMessage", &forwardMessageRequest{
+		ChatID:      toChatID,
+		FromChatID:  fromChatID,
+		MessageID:   messageID,
+		SendOptions: opts,
+	}, func(it *jsoniter.Iterator) error {
+		it.ReadVal(&msg)
+		return it.Error
+	})
+	return msg, err
+}
+
+// copyMessageResult is copyMessage's response shape: unlike forwardMessage,
+// it returns only the new message_id, not a full message.
+type copyMessageResult struct {
+	MessageID int `json:"message_id"`
+}
+
+// CopyMessage copies the message identified by messageID from fromChatID
+// into toChatID without the "Forwarded from" header, returning the new
+// message's ID.
+func (c *Client) CopyMessage(ctx context.Context, toChatID, fromChatID int64, messageID int, opts SendOptions) (int, error) {
+	var result copyMessageResult
+	err := c.Do(ctx, "copyMessage", &forwardMessageRequest{
+		ChatID:      toChatID,
+		FromChatID:  fromChatID,
+		MessageID:   messageID,
+		SendOptions: opts,
+	}, func(it *jsoniter.Iterator) error {
+		it.ReadVal(&result)
+		return it.Error
+	})
+	return result.MessageID, err
+}
@@ -0,0 +1,22 @@
+package telexy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestForwardMessageRequestMarshalling(t *testing.T) {
+	req := forwardMessageRequest{
+		ChatID:      1,
+		FromChatID:  2,
+		MessageID:   3,
+		SendOptions: SendOptions{ProtectContent: true},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, `"protect_content":true`) {
+		t.Fatalf("marshaled %s, missing protect_content", got)
+	}
+}
@@ -0,0 +1,113 @@
+package telexy
+
+import "fmt"
+
+// WebAppInfo describes the Telegram Web App a WithWebApp button launches.
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// LoginURL describes the Telegram Login flow a WithLoginURL button
+// triggers.
+type LoginURL struct {
+	URL                string `json:"url"`
+	ForwardText        string `json:"forward_text,omitempty"`
+	BotUsername        string `json:"bot_username,omitempty"`
+	RequestWriteAccess bool   `json:"request_write_access,omitempty"`
+}
+
+// InlineKeyboardButton is one button of an inline keyboard. Telegram
+// requires exactly one of its action fields to be set; InlineKeyboardBuilder
+// enforces this by construction, and Build validates it for buttons
+// assembled by hand.
+type InlineKeyboardButton struct {
+	Text string `json:"text"`
+
+	CallbackData string      `json:"callback_data,omitempty"`
+	URL          string      `json:"url,omitempty"`
+	WebApp       *WebAppInfo `json:"web_app,omitempty"`
+	LoginURL     *LoginURL   `json:"login_url,omitempty"`
+}
+
+func (btn InlineKeyboardButton) validate() error {
+	n := 0
+	for _, set := range []bool{btn.CallbackData != "", btn.URL != "", btn.WebApp != nil, btn.LoginURL != nil} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("telexy: button %q must set exactly one action, got %d", btn.Text, n)
+	}
+	return nil
+}
+
+// InlineKeyboardMarkup is Telegram's inline_keyboard reply markup: a grid
+// of buttons, built row by row with InlineKeyboardBuilder.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardBuilder builds an InlineKeyboardMarkup one row at a time.
+// The zero value is ready to use.
+type InlineKeyboardBuilder struct {
+	rows [][]InlineKeyboardButton
+	row  []InlineKeyboardButton
+}
+
+// NewInlineKeyboard creates an empty InlineKeyboardBuilder.
+func NewInlineKeyboard() *InlineKeyboardBuilder {
+	return &InlineKeyboardBuilder{}
+}
+
+// Row closes the current row, if it has any buttons, and starts a new one.
+// Calling Build without a trailing Row is fine; it closes the last row
+// automatically.
+func (b *InlineKeyboardBuilder) Row() *InlineKeyboardBuilder {
+	if len(b.row) > 0 {
+		b.rows = append(b.rows, b.row)
+		b.row = nil
+	}
+	return b
+}
+
+// WithCallback appends a button that sends callbackData in a callback_query
+// when pressed.
+func (b *InlineKeyboardBuilder) WithCallback(text, callbackData string) *InlineKeyboardBuilder {
+	b.row = append(b.row, InlineKeyboardButton{Text: text, CallbackData: callbackData})
+	return b
+}
+
+// WithURL appends a button that opens url when pressed.
+func (b *InlineKeyboardBuilder) WithURL(text, url string) *InlineKeyboardBuilder {
+	b.row = append(b.row, InlineKeyboardButton{Text: text, URL: url})
+	return b
+}
+
+// WithWebApp appends a button that launches the Telegram Web App at url
+// when pressed.
+func (b *InlineKeyboardBuilder) WithWebApp(text, url string) *InlineKeyboardBuilder {
+	b.row = append(b.row, InlineKeyboardButton{Text: text, WebApp: &WebAppInfo{URL: url}})
+	return b
+}
+
+// WithLoginURL appends a button that prompts Telegram Login via loginURL
+// when pressed.
+func (b *InlineKeyboardBuilder) WithLoginURL(text string, loginURL LoginURL) *InlineKeyboardBuilder {
+	b.row = append(b.row, InlineKeyboardButton{Text: text, LoginURL: &loginURL})
+	return b
+}
+
+// Build finalizes the keyboard, closing any in-progress row, and validates
+// that every button sets exactly one action.
+func (b *InlineKeyboardBuilder) Build() (InlineKeyboardMarkup, error) {
+	b.Row()
+	for _, row := range b.rows {
+		for _, btn := range row {
+			if err := btn.validate(); err != nil {
+				return InlineKeyboardMarkup{}, err
+			}
+		}
+	}
+	return InlineKeyboardMarkup{InlineKeyboard: b.rows}, nil
+}
@@ -0,0 +1,56 @@
+package telexy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlineKeyboardBuilderMarshalsWebAppAndLoginURL(t *testing.T) {
+	kb, err := NewInlineKeyboard().
+		WithWebApp("Open", "https://example.com/app").
+		Row().
+		WithLoginURL("Login", LoginURL{URL: "https://example.com/login", BotUsername: "examplebot"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		`"web_app":{"url":"https://example.com/app"}`,
+		`"login_url":{"url":"https://example.com/login","bot_username":"examplebot"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("marshaled %s, missing %s", got, want)
+		}
+	}
+}
+
+func TestInlineKeyboardBuilderRejectsMultipleActions(t *testing.T) {
+	kb := NewInlineKeyboard()
+	kb.row = append(kb.row, InlineKeyboardButton{Text: "bad", URL: "https://example.com", CallbackData: "x"})
+
+	if _, err := kb.Build(); err == nil {
+		t.Fatal("expected error for button with multiple actions")
+	}
+}
+
+func TestInlineKeyboardBuilderRowsGroupButtons(t *testing.T) {
+	kb, err := NewInlineKeyboard().
+		WithCallback("a", "a").
+		WithCallback("b", "b").
+		Row().
+		WithCallback("c", "c").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(kb.InlineKeyboard) != 2 || len(kb.InlineKeyboard[0]) != 2 || len(kb.InlineKeyboard[1]) != 1 {
+		t.Fatalf("unexpected row layout: %+v", kb.InlineKeyboard)
+	}
+}
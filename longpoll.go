@@ -0,0 +1,467 @@
+package telexy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/renbou/telexy/api"
+	"github.com/renbou/telexy/retry"
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// LifecycleListener receives coarse lifecycle events from a streamer:
+// start, stop, and recovery from a transient failure. Unlike per-request
+// metrics hooks, these cover the streamer's overall run, so operators can
+// wire them into health checks and alerting without parsing logs.
+//
+// Callbacks are invoked in their own goroutine and must not block the
+// streamer's poll loop.
+type LifecycleListener struct {
+	OnStart   func()
+	OnStop    func(err error)
+	OnRecover func(err error, delay time.Duration)
+}
+
+func (l *LifecycleListener) onStart() {
+	if l != nil && l.OnStart != nil {
+		go l.OnStart()
+	}
+}
+
+func (l *LifecycleListener) onStop(err error) {
+	if l != nil && l.OnStop != nil {
+		go l.OnStop(err)
+	}
+}
+
+func (l *LifecycleListener) onRecover(err error, delay time.Duration) {
+	if l != nil && l.OnRecover != nil {
+		go l.OnRecover(err, delay)
+	}
+}
+
+// LongPollMetrics lets callers observe a long-poll streamer's throughput
+// without depending on any particular metrics library: wire the callbacks
+// into Prometheus counters, StatsD, or whatever else a given bot already
+// uses. Both fields are optional and safe to leave nil.
+//
+// Unlike LifecycleListener's callbacks, these are invoked synchronously on
+// the streamer's poll loop goroutine, since they're expected to be cheap
+// (incrementing a counter) and batches/offset advances are already
+// naturally rate-limited by getUpdates round trips; a callback that blocks
+// or panics will stall polling.
+type LongPollMetrics struct {
+	// OnBatch is called after every successful getUpdates call, reporting
+	// how many updates it returned and how long the call took.
+	OnBatch func(count int, latency time.Duration)
+
+	// OnOffsetAdvance is called whenever the committed offset moves
+	// forward, reporting the new offset.
+	OnOffsetAdvance func(offset int64)
+}
+
+func (m *LongPollMetrics) onBatch(count int, latency time.Duration) {
+	if m != nil && m.OnBatch != nil {
+		m.OnBatch(count, latency)
+	}
+}
+
+func (m *LongPollMetrics) onOffsetAdvance(offset int64) {
+	if m != nil && m.OnOffsetAdvance != nil {
+		m.OnOffsetAdvance(offset)
+	}
+}
+
+// LongPollOptions configures a long-poll Streamer.
+type LongPollOptions[T any] struct {
+	// Timeout is the long-poll timeout sent to getUpdates.
+	Timeout time.Duration
+
+	// Decoder decodes each raw update returned by getUpdates. AsUpdate can
+	// be used when T is Update.
+	Decoder UpdateDecoder[T]
+
+	// Lifecycle, if set, is notified of start/stop/recover events.
+	Lifecycle *LifecycleListener
+
+	// MaxPollBackoff, if positive, caps the backoff used between poll
+	// retries while getUpdates is actively failing, independent of the
+	// Client's own backoff ceiling. Bots for which update freshness
+	// matters more than minimizing retry traffic can set this lower than
+	// the default to recover and resume polling sooner after an outage.
+	MaxPollBackoff time.Duration
+
+	// OffsetStrategy decides when a poll batch's offset may advance. It
+	// defaults to AfterSendOffsetStrategy, matching this streamer's
+	// historical behavior of committing each update once it's handed to
+	// the consumer.
+	OffsetStrategy OffsetStrategy
+
+	// Logger, if set, receives the streamer's logs, tagged with component
+	// "longpoll". Defaults to tlxlog.Nop.
+	Logger tlxlog.Logger
+
+	// AllowedUpdates, if non-empty, is sent as getUpdates' allowed_updates
+	// parameter via api.AllowedUpdates, restricting which update types
+	// Telegram delivers. Left empty, Telegram's default set is used.
+	AllowedUpdates []api.UpdateType
+
+	// LogRawUpdates, if true, logs each update's raw JSON (as received,
+	// before Decoder runs) via Logger. This is meant for diagnosing "why
+	// didn't this update decode" reports, so it's off by default: raw
+	// updates can contain user message text and other sensitive content
+	// that shouldn't end up in production logs unconditionally.
+	LogRawUpdates bool
+
+	// RawUpdateLogLimit caps how many bytes of an update's raw JSON
+	// LogRawUpdates logs, truncating anything longer. Defaults to
+	// DefaultRawUpdateLogLimit. Has no effect unless LogRawUpdates is true.
+	RawUpdateLogLimit int
+
+	// Metrics, if set, is notified of batch throughput and offset
+	// progress. See LongPollMetrics.
+	Metrics *LongPollMetrics
+
+	// WatchdogTimeout, if positive, bounds how long a single getUpdates
+	// call may run before the streamer gives up on it and cancels it,
+	// logging the event, rather than waiting indefinitely. This guards
+	// against a poll that neither returns nor errors (e.g. a
+	// misbehaving proxy or load balancer that accepts the connection but
+	// never forwards a response), which would otherwise wedge the
+	// streamer forever since nothing else times out the call. Set it to
+	// comfortably more than Timeout (Telegram's own long-poll timeout),
+	// since a call legitimately takes close to that long whenever no
+	// updates are pending; a multiple of 2-3x Timeout is a reasonable
+	// starting point. Left zero, no watchdog runs.
+	WatchdogTimeout time.Duration
+
+	// OffsetStore, if set, makes the streamer load its starting offset
+	// from it on the first poll and save progress to it after each batch
+	// advances the offset, so a restart resumes roughly where it left off
+	// instead of starting from zero. A load or save failure is logged and
+	// otherwise ignored; it degrades to the no-store behavior rather than
+	// stopping the streamer.
+	OffsetStore OffsetStore
+}
+
+// DefaultRawUpdateLogLimit is the default value of
+// LongPollOptions.RawUpdateLogLimit: long enough to show a typical
+// update's full structure, short enough to avoid flooding logs with a
+// large media caption or forwarded message.
+const DefaultRawUpdateLogLimit = 2048
+
+type longPollStreamer[T any] struct {
+	client  *Client
+	opts    LongPollOptions[T]
+	offset  int64
+	backoff retry.Backoff
+	logger  tlxlog.Logger
+
+	idleMu      sync.Mutex
+	idle        bool
+	idleWaiters []chan struct{}
+}
+
+// NewLongPoll creates a Streamer that repeatedly calls getUpdates against
+// client, decoding each update with opts.Decoder.
+func NewLongPoll[T any](client *Client, opts LongPollOptions[T]) Streamer[T] {
+	max := maxBackoff
+	if opts.MaxPollBackoff > 0 {
+		max = opts.MaxPollBackoff
+	}
+	if opts.OffsetStrategy == nil {
+		opts.OffsetStrategy = AfterSendOffsetStrategy
+	}
+	if opts.RawUpdateLogLimit <= 0 {
+		opts.RawUpdateLogLimit = DefaultRawUpdateLogLimit
+	}
+	logger := tlxlog.Logger(tlxlog.Nop)
+	if opts.Logger != nil {
+		logger = opts.Logger
+	}
+	return &longPollStreamer[T]{
+		client: client,
+		opts:   opts,
+		backoff: retry.Backoff{
+			Min:    minBackoff,
+			Max:    max,
+			Factor: backoffFactor,
+			Jitter: true,
+		},
+		logger: logger.WithValues("component", tlxlog.ComponentLongPoll),
+	}
+}
+
+func (s *longPollStreamer[T]) Stream(ctx context.Context) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errc := make(chan error, 1)
+
+	go s.run(ctx, out, errc)
+
+	return out, errc
+}
+
+func (s *longPollStreamer[T]) run(ctx context.Context, out chan<- T, errc chan<- error) {
+	defer close(out)
+	defer close(errc)
+
+	s.opts.Lifecycle.onStart()
+
+	if s.opts.OffsetStore != nil {
+		if loaded, err := s.opts.OffsetStore.Load(); err != nil {
+			s.logger.Error("failed to load persisted offset, starting from zero", "err", err)
+		} else {
+			atomic.StoreInt64(&s.offset, loaded)
+		}
+	}
+
+	for {
+		if ctx.Err() != nil {
+			s.opts.Lifecycle.onStop(ctx.Err())
+			return
+		}
+
+		pollStart := time.Now()
+		updates, ids, err := s.pollWithWatchdog(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					// ctx's own deadline elapsed mid-call, before Telegram's
+					// long-poll timeout (sent as getUpdates' "timeout"
+					// parameter) had a chance to return its normal, clean
+					// empty response. Worth a log line, unlike an explicit
+					// Canceled, since it usually means the caller's deadline
+					// is too tight for the network rather than a deliberate
+					// shutdown.
+					s.logger.Error("getUpdates: context deadline exceeded waiting for a response", "err", err)
+				}
+				s.opts.Lifecycle.onStop(ctx.Err())
+				return
+			}
+
+			if retry.IsFatal(err) {
+				// No amount of retrying will fix an invalid/revoked token
+				// or similar permanent failure, so return it immediately
+				// rather than spinning through the backoff loop below.
+				s.logger.Error("getUpdates failed permanently", "err", err)
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+				s.opts.Lifecycle.onStop(err)
+				return
+			}
+
+			delay := s.backoff.Next()
+			s.logger.Error("getUpdates failed", "err", err, "delay", delay)
+			s.opts.Lifecycle.onRecover(err, delay)
+			select {
+			case errc <- err:
+			default:
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				s.opts.Lifecycle.onStop(ctx.Err())
+				return
+			}
+			continue
+		}
+		s.backoff.Reset()
+		s.setIdle(len(updates) == 0)
+		tlxlog.Debug(s.logger, "batch fetched", "count", len(updates), "offset", atomic.LoadInt64(&s.offset))
+		s.opts.Metrics.onBatch(len(updates), time.Since(pollStart))
+
+		delivered := make([]bool, len(updates))
+		canceled := false
+		for i, u := range updates {
+			if canceled {
+				break
+			}
+			select {
+			case out <- u:
+				delivered[i] = true
+			case <-ctx.Done():
+				canceled = true
+			}
+		}
+
+		if newOffset := s.opts.OffsetStrategy.Advance(ids, delivered); newOffset > atomic.LoadInt64(&s.offset) {
+			atomic.StoreInt64(&s.offset, newOffset)
+			s.opts.Metrics.onOffsetAdvance(newOffset)
+			if s.opts.OffsetStore != nil {
+				if err := s.opts.OffsetStore.Save(newOffset); err != nil {
+					s.logger.Error("failed to persist offset", "err", err, "offset", newOffset)
+				}
+			}
+		}
+
+		if canceled {
+			s.opts.Lifecycle.onStop(ctx.Err())
+			return
+		}
+	}
+}
+
+// WaitIdle blocks until the most recent completed poll returned no
+// updates, meaning this streamer has caught up with Telegram's pending
+// update queue at least once. This lets a batch-style "process everything
+// pending, then exit" bot know when it's done, instead of guessing a
+// fixed run duration. It returns ctx's error if ctx is canceled first, and
+// returns immediately if the streamer is already idle.
+func (s *longPollStreamer[T]) WaitIdle(ctx context.Context) error {
+	s.idleMu.Lock()
+	if s.idle {
+		s.idleMu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	s.idleWaiters = append(s.idleWaiters, ch)
+	s.idleMu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Offset returns the update_id through which this streamer has committed
+// progress so far, per opts.OffsetStrategy. It's meant for diagnostics
+// (e.g. a /debug endpoint); callers that need to persist and resume
+// progress should use an OffsetStore instead of polling this.
+func (s *longPollStreamer[T]) Offset() int64 {
+	return atomic.LoadInt64(&s.offset)
+}
+
+func (s *longPollStreamer[T]) setIdle(idle bool) {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	s.idle = idle
+	if !idle {
+		return
+	}
+	for _, ch := range s.idleWaiters {
+		close(ch)
+	}
+	s.idleWaiters = nil
+}
+
+// pollWithWatchdog calls poll, but if opts.WatchdogTimeout is set and
+// elapses before poll returns, cancels poll's context so the stuck call is
+// abandoned and the streamer can loop around to a fresh attempt instead of
+// waiting on it forever. It uses time.AfterFunc rather than a goroutine
+// parked on a select, so letting the timer fire naturally (via defer
+// timer.Stop()) when poll finishes first leaves nothing running behind.
+func (s *longPollStreamer[T]) pollWithWatchdog(ctx context.Context) ([]T, []int64, error) {
+	if s.opts.WatchdogTimeout <= 0 {
+		return s.poll(ctx)
+	}
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	timer := time.AfterFunc(s.opts.WatchdogTimeout, func() {
+		select {
+		case <-done:
+		default:
+			s.logger.Error("watchdog: getUpdates call stuck, canceling it", "timeout", s.opts.WatchdogTimeout)
+			cancel()
+		}
+	})
+	defer timer.Stop()
+
+	updates, ids, err := s.poll(pollCtx)
+	close(done)
+
+	if err != nil && ctx.Err() == nil && pollCtx.Err() != nil {
+		// pollCtx, not ctx, was canceled: the watchdog fired rather than
+		// the caller. Report it as an ordinary transient failure so run's
+		// ctx.Err() check above doesn't mistake this for a shutdown and
+		// return, letting the usual backoff-and-retry loop take over.
+		err = fmt.Errorf("telexy: getUpdates: watchdog canceled a stuck call after %s", s.opts.WatchdogTimeout)
+	}
+	return updates, ids, err
+}
+
+// poll issues a single getUpdates call and decodes every returned update
+// twice: once cheaply for its update_id, and once with the caller's
+// decoder. It does not advance the offset itself; run consults
+// opts.OffsetStrategy with the returned ids once delivery of the batch is
+// known.
+func (s *longPollStreamer[T]) poll(ctx context.Context) ([]T, []int64, error) {
+	var updates []T
+	var ids []int64
+	body := map[string]any{
+		"offset":  atomic.LoadInt64(&s.offset) + 1,
+		"timeout": int(s.opts.Timeout.Seconds()),
+	}
+	if len(s.opts.AllowedUpdates) > 0 {
+		body["allowed_updates"] = api.AllowedUpdates(s.opts.AllowedUpdates...)
+	}
+	err := s.client.Do(ctx, "getUpdates", body, func(it *jsoniter.Iterator) error {
+		it.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+			raw := it.SkipAndReturnBytes()
+			if s.opts.LogRawUpdates {
+				s.logger.Info("raw update", "json", truncateRaw(raw, s.opts.RawUpdateLogLimit))
+			}
+
+			idIt := json.BorrowIterator(raw)
+			id := readUpdateID(idIt)
+			json.ReturnIterator(idIt)
+
+			valIt := json.BorrowIterator(raw)
+			u, err := s.opts.Decoder(valIt)
+			json.ReturnIterator(valIt)
+			if err != nil {
+				return false
+			}
+
+			updates = append(updates, u)
+			ids = append(ids, id)
+			return true
+		})
+		return it.Error
+	})
+	if err != nil {
+		if IsFatalAPIError(err) {
+			err = retry.Fatal(err)
+		}
+		return nil, nil, fmt.Errorf("telexy: getUpdates: %w", err)
+	}
+	return updates, ids, nil
+}
+
+// truncateRaw returns raw as a string, cut to at most limit bytes with a
+// "...(truncated)" marker appended, so a single oversized update can't
+// flood the log.
+func truncateRaw(raw []byte, limit int) string {
+	if len(raw) <= limit {
+		return string(raw)
+	}
+	return string(raw[:limit]) + "...(truncated)"
+}
+
+func readUpdateID(it *jsoniter.Iterator) int64 {
+	var id int64
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		if field == "update_id" {
+			id = it.ReadInt64()
+		} else {
+			it.Skip()
+		}
+		return it.Error == nil
+	})
+	return id
+}
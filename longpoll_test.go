@@ -0,0 +1,780 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/api"
+	"github.com/renbou/telexy/retry"
+)
+
+func TestLongPollStreamerDecodesAndAdvancesOffset(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if n == 1 {
+			w.Write([]byte(`{"ok":true,"result":[
+				{"update_id":1,"message":{"text":"/start","chat":{"id":10},"from":{"id":20}}},
+				{"update_id":2,"message":{"text":"hi"}}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("", "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	var got []Update
+	for i := 0; i < 2; i++ {
+		select {
+		case u := <-out:
+			got = append(got, u)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for updates")
+		}
+	}
+
+	if got[0].Content.Text != "/start" || got[0].Content.ChatID != 10 {
+		t.Fatalf("unexpected first update: %+v", got[0])
+	}
+	if got[1].Content.Text != "hi" {
+		t.Fatalf("unexpected second update: %+v", got[1])
+	}
+	if s := lp.(*longPollStreamer[Update]); s.offset != 2 {
+		t.Fatalf("expected offset to advance to 2, got %d", s.offset)
+	}
+}
+
+func TestLongPollStreamerSendsConfiguredAllowedUpdates(t *testing.T) {
+	var mu sync.Mutex
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		gotBody = body
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{
+		Decoder:        AsUpdate,
+		AllowedUpdates: []api.UpdateType{api.UpdateTypeMessage, api.UpdateTypeCallbackQuery},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, _ = lp.Stream(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	var body map[string]any
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		body = gotBody
+		mu.Unlock()
+		if body != nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	got, ok := body["allowed_updates"].([]any)
+	if !ok || len(got) != 2 || got[0] != "message" || got[1] != "callback_query" {
+		t.Fatalf("got allowed_updates %v, want [message callback_query]", body["allowed_updates"])
+	}
+}
+
+func TestLongPollStreamerLogsRawUpdatesWhenEnabled(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":1,"message":{"text":"hi"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{
+		Decoder:       AsUpdate,
+		Logger:        logger,
+		LogRawUpdates: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	select {
+	case <-out:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for update")
+	}
+
+	found := false
+	for _, entry := range logger.entriesForComponent("longpoll") {
+		if entry == "raw update" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected a raw update log entry tagged component=longpoll")
+	}
+}
+
+// TestLongPollStreamerAcceptsUpdateIDInAnyPosition guards against a
+// regression of field-order sensitivity in poll's getUpdates consumer.
+// poll already reads each update via SkipAndReturnBytes and then makes two
+// independent passes over the resulting raw bytes (one for update_id, one
+// for the caller's Decoder), so update_id doesn't need to appear first in
+// the object for either pass to find it.
+func TestLongPollStreamerAcceptsUpdateIDInAnyPosition(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte(`{"ok":true,"result":[{"message":{"text":"hi"},"update_id":2}]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	select {
+	case u := <-out:
+		if u.Content.Text != "hi" {
+			t.Fatalf("unexpected update: %+v", u)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for update")
+	}
+
+	if s := lp.(*longPollStreamer[Update]); s.offset != 2 {
+		t.Fatalf("expected offset to advance to 2 (the update_id despite appearing last), got %d", s.offset)
+	}
+}
+
+func TestLongPollStreamerWaitIdleUnblocksAfterEmptyPoll(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":1,"message":{"text":"hi"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	select {
+	case <-out:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the one pending update")
+	}
+
+	s := lp.(*longPollStreamer[Update])
+	if err := s.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle: %v", err)
+	}
+}
+
+func TestLongPollStreamerWaitIdleRespectsContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":1,"message":{"text":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	defer streamCancel()
+	out, _ := lp.Stream(streamCtx)
+	go func() {
+		for range out {
+		}
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+
+	s := lp.(*longPollStreamer[Update])
+	if err := s.WaitIdle(waitCtx); err != context.DeadlineExceeded {
+		t.Fatalf("WaitIdle = %v, want context.DeadlineExceeded (every poll here returns an update, never idle)", err)
+	}
+}
+
+func TestLongPollStreamerLogsNetworkTimeoutsDistinctlyFromOtherFailures(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	hc := &http.Client{Timeout: 20 * time.Millisecond}
+	c, err := NewClient(srv.URL, "tok", hc, &ClientOpts{RetryBudget: retry.NewBudget(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, Logger: logger})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, errc := lp.Stream(ctx)
+
+	select {
+	case <-errc:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for poll error")
+	}
+
+	entries := logger.entriesForComponent("longpoll")
+	found := false
+	for _, e := range entries {
+		if e == "getUpdates failed: network timeout" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a network-timeout-tagged log entry, got %v", entries)
+	}
+}
+
+func TestNewLongPollAppliesMaxPollBackoff(t *testing.T) {
+	c, err := NewClient("", "tok", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{
+		Decoder:        AsUpdate,
+		MaxPollBackoff: 2 * time.Second,
+	})
+	s := lp.(*longPollStreamer[Update])
+	if s.backoff.Max != 2*time.Second {
+		t.Fatalf("backoff.Max = %v, want 2s", s.backoff.Max)
+	}
+}
+
+func TestNewLongPollDefaultsMaxPollBackoff(t *testing.T) {
+	c, err := NewClient("", "tok", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+	s := lp.(*longPollStreamer[Update])
+	if s.backoff.Max != maxBackoff {
+		t.Fatalf("backoff.Max = %v, want %v", s.backoff.Max, maxBackoff)
+	}
+}
+
+func TestLongPollStreamerLogsFailuresAsComponentLongPoll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"ok":false,"error_code":500,"description":"boom"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{RetryBudget: retry.NewBudget(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, Logger: logger})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, errc := lp.Stream(ctx)
+
+	select {
+	case <-errc:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for poll error")
+	}
+
+	if entries := logger.entriesForComponent("longpoll"); len(entries) == 0 {
+		t.Fatal("expected at least one log entry tagged component=longpoll")
+	}
+}
+
+// TestLongPollStreamerStopsPromptlyOnCancellationDuringBackoff guards
+// run's existing cancellation handling during its inter-attempt sleep: it
+// selects the backoff timer against ctx.Done(), so canceling the stream
+// doesn't have to wait out the full backoff delay before the streamer
+// notices and shuts down.
+func TestLongPollStreamerStopsPromptlyOnCancellationDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"ok":false,"error_code":500,"description":"boom"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, MaxPollBackoff: 10 * time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errc := lp.Stream(ctx)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	done := false
+	for !done {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				done = true
+			}
+		case _, ok := <-errc:
+			if !ok {
+				done = true
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("streamer took too long to shut down after cancellation")
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("streamer took %v to shut down, want well under the 10s backoff", elapsed)
+	}
+}
+
+// TestLongPollStreamerLogsContextDeadlineExceeded guards run's distinction
+// between a ctx deadline elapsing mid-poll (worth a log line, since it
+// usually means the caller's deadline is too tight for the network) and an
+// explicit cancellation (an expected shutdown, logged nowhere).
+func TestLongPollStreamerLogsContextDeadlineExceeded(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, Logger: logger})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	out, errc := lp.Stream(ctx)
+
+	for ok := true; ok; {
+		select {
+		case _, ok = <-out:
+		case _, ok = <-errc:
+		case <-time.After(2 * time.Second):
+			t.Fatal("streamer took too long to shut down after deadline")
+		}
+	}
+
+	found := false
+	for _, entry := range logger.entriesForComponent("longpoll") {
+		if strings.Contains(entry, "deadline exceeded") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a deadline-exceeded log entry, got %v", logger.entriesForComponent("longpoll"))
+	}
+}
+
+// TestLongPollStreamerDoesNotLogExplicitCancellation guards the other half
+// of the same distinction: an explicit cancel() is an expected shutdown and
+// shouldn't produce the deadline-exceeded log line.
+func TestLongPollStreamerDoesNotLogExplicitCancellation(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, Logger: logger})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errc := lp.Stream(ctx)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	for ok := true; ok; {
+		select {
+		case _, ok = <-out:
+		case _, ok = <-errc:
+		case <-time.After(2 * time.Second):
+			t.Fatal("streamer took too long to shut down after cancellation")
+		}
+	}
+
+	for _, entry := range logger.entriesForComponent("longpoll") {
+		if strings.Contains(entry, "deadline exceeded") {
+			t.Fatalf("unexpected deadline-exceeded log entry on explicit cancellation: %v", entry)
+		}
+	}
+}
+
+// TestLongPollStreamerStopsImmediatelyOnFatalAPIError guards run's
+// short-circuit for permanent failures: a 401 (revoked token) should end
+// the stream on the first attempt instead of retrying through the backoff
+// loop.
+func TestLongPollStreamerStopsImmediatelyOnFatalAPIError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, MaxPollBackoff: 10 * time.Second})
+
+	out, errc := lp.Stream(context.Background())
+
+	var gotErr error
+	for ok := true; ok; {
+		select {
+		case _, ok = <-out:
+		case e, ok2 := <-errc:
+			ok = ok2
+			if ok2 {
+				gotErr = e
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("streamer took too long to stop after a fatal error")
+		}
+	}
+
+	if gotErr == nil || !IsFatalAPIError(gotErr) {
+		t.Fatalf("gotErr = %v, want a fatal *APIError", gotErr)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("getUpdates called %d times, want exactly 1", n)
+	}
+}
+
+// TestLongPollStreamerEmitsDebugLogPerBatch guards the opt-in Debug line
+// emitted for each batch fetched, reached via tlxlog.Debug since the
+// recording logger implements DebugLogger.
+func TestLongPollStreamerEmitsDebugLogPerBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":1,"message":{"message_id":1,"chat":{"id":1,"type":"private"},"text":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, Logger: logger})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errc := lp.Stream(ctx)
+	<-out
+	cancel()
+	for range out {
+	}
+	for range errc {
+	}
+
+	found := false
+	for _, entry := range logger.entriesForComponent("longpoll") {
+		if strings.Contains(entry, "batch fetched") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a batch fetched debug entry, got %v", logger.entriesForComponent("longpoll"))
+	}
+}
+
+func TestLongPollStreamerOffsetAdvancesAfterDelivery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":5,"message":{"text":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+	s := lp.(*longPollStreamer[Update])
+
+	if got := s.Offset(); got != 0 {
+		t.Fatalf("Offset() = %d before any poll, want 0", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+	<-out
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if s.Offset() == 5 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Offset() = %d, want 5", s.Offset())
+}
+
+func TestLongPollStreamerResumesFromOffsetStore(t *testing.T) {
+	var gotOffset int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Offset int64 `json:"offset"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		atomic.StoreInt64(&gotOffset, body.Offset)
+		w.Write([]byte(`{"ok":true,"result":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+	if err := store.Save(100); err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, OffsetStore: store})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, errc := lp.Stream(ctx)
+	defer func() {
+		cancel()
+		for range out {
+		}
+		for range errc {
+		}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if atomic.LoadInt64(&gotOffset) == 101 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("getUpdates offset = %d, want 101 (loaded 100 + 1)", atomic.LoadInt64(&gotOffset))
+}
+
+func TestLongPollStreamerSavesOffsetAfterBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":9,"message":{"text":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, OffsetStore: store})
+	ctx, cancel := context.WithCancel(context.Background())
+	out, _ := lp.Stream(ctx)
+	<-out
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if offset, _ := store.Load(); offset == 9 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	offset, _ := store.Load()
+	t.Fatalf("persisted offset = %d, want 9", offset)
+}
+
+func TestLongPollStreamerInvokesMetricsOnBatchAndOffsetAdvance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":9,"message":{"text":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var batchCount atomic.Int64
+	var sawLatency atomic.Bool
+	var advancedOffset atomic.Int64
+	metrics := &LongPollMetrics{
+		OnBatch: func(count int, latency time.Duration) {
+			batchCount.Add(int64(count))
+			if latency >= 0 {
+				sawLatency.Store(true)
+			}
+		},
+		OnOffsetAdvance: func(offset int64) {
+			advancedOffset.Store(offset)
+		},
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate, Metrics: metrics})
+	ctx, cancel := context.WithCancel(context.Background())
+	out, _ := lp.Stream(ctx)
+	<-out
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if batchCount.Load() == 1 && advancedOffset.Load() == 9 && sawLatency.Load() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("batchCount = %d, advancedOffset = %d, sawLatency = %v", batchCount.Load(), advancedOffset.Load(), sawLatency.Load())
+}
+
+func TestLongPollStreamerWatchdogCancelsStuckPoll(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{RetryBudget: retry.NewBudget(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := newRecordingLogger()
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{
+		Decoder:         AsUpdate,
+		Logger:          logger,
+		WatchdogTimeout: 20 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, errc := lp.Stream(ctx)
+
+	select {
+	case err := <-errc:
+		if err == nil || !strings.Contains(err.Error(), "watchdog") {
+			t.Fatalf("got %v, want a watchdog-tagged error", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the watchdog to cancel the stuck poll")
+	}
+
+	entries := logger.entriesForComponent("longpoll")
+	found := false
+	for _, e := range entries {
+		if strings.Contains(e, "watchdog") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a watchdog log entry, got %v", entries)
+	}
+}
+
+func TestLongPollStreamerNoWatchdogByDefault(t *testing.T) {
+	c, err := NewClient("", "tok", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lp := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+	s := lp.(*longPollStreamer[Update])
+	if s.opts.WatchdogTimeout != 0 {
+		t.Fatalf("WatchdogTimeout = %v, want 0 by default", s.opts.WatchdogTimeout)
+	}
+}
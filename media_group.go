@@ -0,0 +1,98 @@
+package telexy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// InputMedia describes one item of a sendMediaGroup call.
+type InputMedia struct {
+	// Type is one of "photo", "video" or "document".
+	Type string `json:"type"`
+
+	// Media is either a file_id, an HTTP URL, or an "attach://<name>"
+	// reference wired up automatically when Upload is set.
+	Media string `json:"media"`
+
+	Caption string `json:"caption,omitempty"`
+
+	// Upload, if non-nil, is attached to the multipart request and
+	// referenced by Media via "attach://".
+	Upload io.Reader `json:"-"`
+}
+
+var validMediaTypes = map[string]bool{"photo": true, "video": true, "document": true}
+
+// SendMediaGroup sends 2-10 photos, videos or documents as an album via
+// sendMediaGroup, uploading any InputMedia.Upload readers as multipart file
+// parts wired up via "attach://" references.
+func (c *Client) SendMediaGroup(ctx context.Context, chatID int64, media []InputMedia) ([]Message, error) {
+	if len(media) < 2 || len(media) > 10 {
+		return nil, fmt.Errorf("telexy: SendMediaGroup: media must have 2-10 items, got %d", len(media))
+	}
+	for _, m := range media {
+		if !validMediaTypes[m.Type] {
+			return nil, fmt.Errorf("telexy: SendMediaGroup: unsupported media type %q", m.Type)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+
+	if err := w.WriteField("chat_id", fmt.Sprintf("%d", chatID)); err != nil {
+		return nil, fmt.Errorf("telexy: write chat_id field: %w", err)
+	}
+
+	attachMedia := make([]InputMedia, len(media))
+	for i, m := range media {
+		attachMedia[i] = m
+		if m.Upload == nil {
+			continue
+		}
+		name := fmt.Sprintf("file%d", i)
+		attachMedia[i].Media = "attach://" + name
+		part, err := w.CreateFormFile(name, name)
+		if err != nil {
+			return nil, fmt.Errorf("telexy: create form file: %w", err)
+		}
+		if _, err := io.Copy(part, m.Upload); err != nil {
+			return nil, fmt.Errorf("telexy: copy upload: %w", err)
+		}
+	}
+
+	mediaJSON, err := json.Marshal(attachMedia)
+	if err != nil {
+		return nil, fmt.Errorf("telexy: marshal media: %w", err)
+	}
+	if err := w.WriteField("media", string(mediaJSON)); err != nil {
+		return nil, fmt.Errorf("telexy: write media field: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("telexy: close multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.methodURL("sendMediaGroup"), buf)
+	if err != nil {
+		return nil, fmt.Errorf("telexy: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("telexy: do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var messages []Message
+	err = readResponse("sendMediaGroup", resp, func(it *jsoniter.Iterator) error {
+		it.ReadVal(&messages)
+		return it.Error
+	})
+	return messages, err
+}
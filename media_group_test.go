@@ -0,0 +1,26 @@
+package telexy
+
+import "testing"
+
+func TestSendMediaGroupValidatesCount(t *testing.T) {
+	c := &Client{}
+	if _, err := c.SendMediaGroup(nil, 1, []InputMedia{{Type: "photo", Media: "file_id"}}); err == nil {
+		t.Fatal("expected error for fewer than 2 media items")
+	}
+
+	tooMany := make([]InputMedia, 11)
+	for i := range tooMany {
+		tooMany[i] = InputMedia{Type: "photo", Media: "file_id"}
+	}
+	if _, err := c.SendMediaGroup(nil, 1, tooMany); err == nil {
+		t.Fatal("expected error for more than 10 media items")
+	}
+}
+
+func TestSendMediaGroupValidatesType(t *testing.T) {
+	c := &Client{}
+	media := []InputMedia{{Type: "photo", Media: "a"}, {Type: "sticker", Media: "b"}}
+	if _, err := c.SendMediaGroup(nil, 1, media); err == nil {
+		t.Fatal("expected error for unsupported media type")
+	}
+}
@@ -0,0 +1,110 @@
+package telexy
+
+import (
+	"context"
+	"sync"
+)
+
+// EstimateUpdateSize approximates u's retained memory footprint in bytes,
+// covering the variable-length fields that dominate a typical update's
+// size. It's intentionally rough: exact accounting would require
+// reflecting over whatever a caller's own UpdateDecoder produces, which
+// PipelineMemoryLimiter has no visibility into when T isn't Update.
+func EstimateUpdateSize(u Update) int64 {
+	const fixedOverhead = 64 // scalar fields: IDs, Type, ChatID, UserID, Date
+
+	size := int64(fixedOverhead)
+	size += int64(len(u.Content.Text))
+	size += int64(len(u.Content.CallbackID))
+	size += int64(len(u.Content.Entities)) * 32 // rough per-entity overhead
+	return size
+}
+
+// PipelineMemoryLimiter bounds the approximate memory a Pipeline holds for
+// updates handed off from its streamer to its Mux. Acquire blocks the
+// hand-off once estimated usage would exceed Ceiling, which in turn blocks
+// the streamer's own send (Streamer's output channel is unbuffered), so a
+// slow Mux applies backpressure all the way back to polling rather than
+// letting buffered updates grow without bound.
+//
+// This only accounts for the single streamer->Mux hand-off point; it
+// doesn't track memory held in per-subscription channels downstream of
+// Mux.Dispatch, since those already shed load by dropping updates rather
+// than buffering them indefinitely. The zero value is not usable; use
+// NewPipelineMemoryLimiter. A PipelineMemoryLimiter is safe for concurrent
+// use.
+type PipelineMemoryLimiter struct {
+	ceiling  int64
+	estimate func(Update) int64
+
+	mu      sync.Mutex
+	usage   int64
+	waiters []chan struct{}
+}
+
+// NewPipelineMemoryLimiter creates a PipelineMemoryLimiter with the given
+// ceiling in bytes. A ceiling of 0 disables the limit. If estimate is nil,
+// EstimateUpdateSize is used.
+func NewPipelineMemoryLimiter(ceiling int64, estimate func(Update) int64) *PipelineMemoryLimiter {
+	if estimate == nil {
+		estimate = EstimateUpdateSize
+	}
+	return &PipelineMemoryLimiter{ceiling: ceiling, estimate: estimate}
+}
+
+// Acquire blocks until accounting for u would not exceed the ceiling (an
+// update already in flight always makes progress: a single update larger
+// than the ceiling is admitted rather than deadlocking), then reserves its
+// estimated size and returns it for a matching Release. It returns ctx's
+// error if ctx is canceled first.
+func (l *PipelineMemoryLimiter) Acquire(ctx context.Context, u Update) (int64, error) {
+	size := l.estimate(u)
+
+	for {
+		l.mu.Lock()
+		if l.ceiling <= 0 || l.usage == 0 || l.usage+size <= l.ceiling {
+			l.usage += size
+			l.mu.Unlock()
+			return size, nil
+		}
+		ready := make(chan struct{})
+		l.waiters = append(l.waiters, ready)
+		l.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// Release returns size, as previously returned by Acquire, to the budget
+// and wakes any callers blocked in Acquire.
+func (l *PipelineMemoryLimiter) Release(size int64) {
+	l.mu.Lock()
+	l.usage -= size
+	if l.usage < 0 {
+		l.usage = 0
+	}
+	waiters := l.waiters
+	l.waiters = nil
+	l.mu.Unlock()
+
+	for _, w := range waiters {
+		close(w)
+	}
+}
+
+// Usage returns the current estimated bytes reserved by in-flight updates.
+func (l *PipelineMemoryLimiter) Usage() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.usage
+}
+
+// Ceiling returns the configured byte ceiling, or 0 if the limiter is
+// unbounded.
+func (l *PipelineMemoryLimiter) Ceiling() int64 {
+	return l.ceiling
+}
@@ -0,0 +1,96 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEstimateUpdateSizeGrowsWithContent(t *testing.T) {
+	small := EstimateUpdateSize(Update{})
+	big := EstimateUpdateSize(Update{Content: Content{Text: "this is a much longer message body"}})
+	if big <= small {
+		t.Fatalf("big estimate %d should exceed small estimate %d", big, small)
+	}
+}
+
+func TestPipelineMemoryLimiterBlocksUntilReleased(t *testing.T) {
+	l := NewPipelineMemoryLimiter(100, func(Update) int64 { return 60 })
+
+	ctx := context.Background()
+	size1, err := l.Acquire(ctx, Update{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Usage() != 60 {
+		t.Fatalf("usage = %d, want 60", l.Usage())
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		size2, err := l.Acquire(context.Background(), Update{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		l.Release(size2)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire returned before the first was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	l.Release(size1)
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second Acquire never returned after Release")
+	}
+}
+
+func TestPipelineMemoryLimiterCtxCancellation(t *testing.T) {
+	l := NewPipelineMemoryLimiter(100, func(Update) int64 { return 60 })
+
+	size, err := l.Acquire(context.Background(), Update{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release(size)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.Acquire(ctx, Update{}); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPipelineMemoryLimiterAdmitsOversizedSingleUpdate(t *testing.T) {
+	l := NewPipelineMemoryLimiter(10, func(Update) int64 { return 1000 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	size, err := l.Acquire(ctx, Update{})
+	if err != nil {
+		t.Fatalf("expected a lone oversized update to be admitted, got %v", err)
+	}
+	l.Release(size)
+}
+
+func TestPipelineMemoryLimiterUnboundedWithZeroCeiling(t *testing.T) {
+	l := NewPipelineMemoryLimiter(0, func(Update) int64 { return 1 << 30 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		if _, err := l.Acquire(ctx, Update{}); err != nil {
+			t.Fatalf("unbounded limiter should never block: %v", err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package telexy
+
+import (
+	"context"
+	"time"
+)
+
+// MergeFragments relays updates from in to the returned Stream, merging
+// runs of consecutive message updates from the same ChatID and UserID
+// whose Content.Date (or arrival time, for updates with no date) falls
+// within window of the previous one into a single update: the fragments'
+// Content.Text are joined with "\n" and the merged update otherwise keeps
+// the first fragment's fields.
+//
+// This is a heuristic, opt-in aid for bots (note-taking, transcription)
+// where a Telegram client splits one long message the user typed into
+// several consecutive updates; telexy has no reliable way to tell that
+// apart from the user genuinely sending several short messages in a row,
+// so a caller should only use this when that tradeoff fits their bot.
+// Update types other than plain messages (ChatID == 0 or UserID == 0)
+// always pass through unmerged.
+//
+// A pending merge is flushed once window has elapsed since its last
+// fragment, or immediately once in closes. The returned stream closes once
+// ctx is canceled or in closes.
+func MergeFragments(ctx context.Context, in Stream[Update], window time.Duration) Stream[Update] {
+	out := make(chan Update)
+
+	go func() {
+		defer close(out)
+
+		var pending *Update
+		timer := time.NewTimer(window)
+		if !timer.Stop() {
+			<-timer.C
+		}
+		defer timer.Stop()
+
+		// flushFired sends pending (which must be non-nil) without touching
+		// the timer, for use right after a <-timer.C receive already
+		// consumed it.
+		flushFired := func() bool {
+			u := *pending
+			pending = nil
+			select {
+			case out <- u:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// flush stops any still-running timer before sending pending, for
+		// use everywhere else pending needs to be emitted early.
+		flush := func() bool {
+			if pending == nil {
+				return true
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			return flushFired()
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				if !flushFired() {
+					return
+				}
+			case u, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				if u.Content.ChatID == 0 && u.Content.UserID == 0 {
+					if !flush() {
+						return
+					}
+					select {
+					case out <- u:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+				if pending != nil && pending.Content.ChatID == u.Content.ChatID && pending.Content.UserID == u.Content.UserID {
+					if !timer.Stop() {
+						<-timer.C
+					}
+					pending.Content.Text += "\n" + u.Content.Text
+				} else {
+					if !flush() {
+						return
+					}
+					pending = &u
+				}
+				timer.Reset(window)
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,105 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func fragmentUpdate(chatID, userID int64, text string) Update {
+	var u Update
+	u.Content.ChatID = chatID
+	u.Content.UserID = userID
+	u.Content.Text = text
+	return u
+}
+
+func TestMergeFragmentsJoinsConsecutiveSameUserMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := MergeFragments(ctx, in, 50*time.Millisecond)
+
+	in <- fragmentUpdate(1, 1, "hello")
+	in <- fragmentUpdate(1, 1, "world")
+	close(in)
+
+	select {
+	case u, ok := <-out:
+		if !ok {
+			t.Fatal("stream closed before delivering the merged update")
+		}
+		if u.Content.Text != "hello\nworld" {
+			t.Fatalf("merged text = %q, want %q", u.Content.Text, "hello\nworld")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for merged update")
+	}
+
+	if _, ok := <-out; ok {
+		t.Fatal("expected stream to close after the merged update")
+	}
+}
+
+func TestMergeFragmentsKeepsDifferentChatsSeparate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := MergeFragments(ctx, in, 50*time.Millisecond)
+
+	in <- fragmentUpdate(1, 1, "from chat 1")
+	in <- fragmentUpdate(2, 1, "from chat 2")
+	close(in)
+
+	var texts []string
+	for u := range out {
+		texts = append(texts, u.Content.Text)
+	}
+	if len(texts) != 2 || texts[0] != "from chat 1" || texts[1] != "from chat 2" {
+		t.Fatalf("unexpected texts: %v", texts)
+	}
+}
+
+func TestMergeFragmentsFlushesAfterWindowElapses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := MergeFragments(ctx, in, 30*time.Millisecond)
+	defer close(in)
+
+	in <- fragmentUpdate(1, 1, "first")
+
+	select {
+	case u := <-out:
+		if u.Content.Text != "first" {
+			t.Fatalf("flushed text = %q, want %q", u.Content.Text, "first")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the window to flush the pending fragment")
+	}
+}
+
+func TestMergeFragmentsPassesThroughNonMessageUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Update)
+	out := MergeFragments(ctx, in, 50*time.Millisecond)
+
+	var callback Update
+	callback.Content.CallbackID = "cb1"
+	in <- callback
+	close(in)
+
+	select {
+	case u := <-out:
+		if u.Content.CallbackID != "cb1" {
+			t.Fatalf("unexpected passthrough update: %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for passthrough update")
+	}
+}
@@ -0,0 +1,9 @@
+package telexy
+
+// Message is a minimal representation of a Telegram message, covering the
+// fields telexy itself needs. Callers wanting the full shape should decode
+// into their own type.
+type Message struct {
+	ID   int   `json:"message_id"`
+	Date int64 `json:"date"`
+}
@@ -0,0 +1,66 @@
+package telexy
+
+import (
+	"io"
+	"sync"
+)
+
+// countingReader wraps an io.Reader, counting the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ResponseSizeTracker accumulates response body sizes observed via
+// ClientOpts.OnResponseSize, for capacity planning (e.g. right-sizing
+// decode buffers for a bot's actual getUpdates traffic).
+type ResponseSizeTracker struct {
+	mu    sync.Mutex
+	count int64
+	sum   int64
+	max   int64
+}
+
+// Observe records a single response body size. It has the signature
+// expected by ClientOpts.OnResponseSize, ignoring the method.
+func (t *ResponseSizeTracker) Observe(method string, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.count++
+	t.sum += size
+	if size > t.max {
+		t.max = size
+	}
+}
+
+// Count returns the number of observed responses.
+func (t *ResponseSizeTracker) Count() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count
+}
+
+// Max returns the largest observed response size.
+func (t *ResponseSizeTracker) Max() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.max
+}
+
+// Mean returns the average observed response size, or 0 if nothing has
+// been observed yet.
+func (t *ResponseSizeTracker) Mean() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.count == 0 {
+		return 0
+	}
+	return float64(t.sum) / float64(t.count)
+}
@@ -0,0 +1,51 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseSizeTracker(t *testing.T) {
+	var tr ResponseSizeTracker
+	tr.Observe("getUpdates", 10)
+	tr.Observe("getUpdates", 30)
+
+	if got := tr.Count(); got != 2 {
+		t.Fatalf("Count() = %d, want 2", got)
+	}
+	if got := tr.Max(); got != 30 {
+		t.Fatalf("Max() = %d, want 30", got)
+	}
+	if got := tr.Mean(); got != 20 {
+		t.Fatalf("Mean() = %v, want 20", got)
+	}
+}
+
+func TestClientRecordsResponseSize(t *testing.T) {
+	body := []byte(`{"ok":true,"result":{"message_id":1}}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	var tr ResponseSizeTracker
+	c, err := NewClient("", "tok", srv.Client(), &ClientOpts{OnResponseSize: tr.Observe})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+
+	if err := c.Do(context.Background(), "sendMessage", nil, nil); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+
+	if got := tr.Count(); got != 1 {
+		t.Fatalf("Count() = %d, want 1", got)
+	}
+	if got := tr.Max(); got != int64(len(body)) {
+		t.Fatalf("Max() = %d, want %d", got, len(body))
+	}
+}
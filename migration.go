@@ -0,0 +1,15 @@
+package telexy
+
+// chatRedirector is implemented by request bodies that target a single
+// chat and can be redirected to a different one, letting Do retry them
+// against APIError.MigrateToChatID after a group migrates to a supergroup.
+type chatRedirector interface {
+	chatID() int64
+	redirectChatID(int64)
+}
+
+func (r *SendMessageRequest) chatID() int64           { return r.ChatID }
+func (r *SendMessageRequest) redirectChatID(id int64) { r.ChatID = id }
+
+func (r *forwardMessageRequest) chatID() int64           { return r.ChatID }
+func (r *forwardMessageRequest) redirectChatID(id int64) { r.ChatID = id }
@@ -0,0 +1,79 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDoRedirectsChatIDOnMigration(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"group migrated","parameters":{"migrate_to_chat_id":-1009}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	var migrated [2]int64
+	c, err := NewClient("", "tok", srv.Client(), &ClientOpts{
+		OnMigration: func(oldChatID, newChatID int64) {
+			migrated = [2]int64{oldChatID, newChatID}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+	c.backoff.Min = 0
+
+	req := &SendMessageRequest{ChatID: -42, Text: "hi"}
+	if err := c.Do(context.Background(), "sendMessage", req, nil); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("got %d calls, want 2 (one hop)", calls)
+	}
+	if req.ChatID != -1009 {
+		t.Fatalf("request not redirected, ChatID = %d", req.ChatID)
+	}
+	if migrated != [2]int64{-42, -1009} {
+		t.Fatalf("OnMigration called with %v, want [-42 -1009]", migrated)
+	}
+}
+
+func TestDoDoesNotRedirectWithoutOnMigration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"group migrated","parameters":{"migrate_to_chat_id":-1009}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient("", "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.endpointURL.Scheme = "http"
+	c.endpointURL.Host = srv.Listener.Addr().String()
+	c.backoff.Min = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req := &SendMessageRequest{ChatID: -42, Text: "hi"}
+	err = c.Do(ctx, "sendMessage", req, nil)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if req.ChatID != -42 {
+		t.Fatalf("ChatID redirected to %d without OnMigration configured", req.ChatID)
+	}
+}
@@ -0,0 +1,102 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/renbou/telexy/mux"
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// BotConfig pairs a bot's token and update stream with the Mux that should
+// receive its updates.
+type BotConfig struct {
+	Token    string
+	Streamer Streamer[Update]
+	Mux      *mux.Mux
+}
+
+// MultiBot runs several independent bots in one process. Every bot gets its
+// own Client and Mux, but they share a single http.Client, and each bot's
+// logs are tagged with its identity via Logger.WithValues.
+type MultiBot struct {
+	logger tlxlog.Logger
+	bots   []*multiBotEntry
+}
+
+type multiBotEntry struct {
+	client *Client
+	cfg    BotConfig
+	cancel context.CancelFunc
+}
+
+// NewMultiBot creates a MultiBot for the given configs, using hc (or
+// http.DefaultClient if nil) for every underlying Client.
+func NewMultiBot(configs []BotConfig, hc *http.Client, logger tlxlog.Logger) (*MultiBot, error) {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	if logger == nil {
+		logger = tlxlog.Nop
+	}
+
+	mb := &MultiBot{logger: logger}
+	for _, cfg := range configs {
+		client, err := NewClient("", cfg.Token, hc, nil)
+		if err != nil {
+			return nil, err
+		}
+		mb.bots = append(mb.bots, &multiBotEntry{client: client, cfg: cfg})
+	}
+	return mb, nil
+}
+
+// Start begins streaming updates for every bot and dispatching them into
+// each bot's Mux, until ctx is canceled or Stop is called.
+func (mb *MultiBot) Start(ctx context.Context) {
+	for _, b := range mb.bots {
+		botCtx, cancel := context.WithCancel(ctx)
+		b.cancel = cancel
+
+		botLogger := mb.logger.WithValues("bot", botIdentity(b.cfg.Token))
+		go b.run(botCtx, botLogger)
+	}
+}
+
+func (b *multiBotEntry) run(ctx context.Context, logger tlxlog.Logger) {
+	updates, errs := b.cfg.Streamer.Stream(ctx)
+	for {
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				return
+			}
+			b.cfg.Mux.Dispatch(u)
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			if err != nil {
+				logger.Error("stream error", "err", err)
+			}
+		}
+	}
+}
+
+// Stop cancels every bot's update stream.
+func (mb *MultiBot) Stop() {
+	for _, b := range mb.bots {
+		if b.cancel != nil {
+			b.cancel()
+		}
+	}
+}
+
+// botIdentity returns a short, non-sensitive identifier for token suitable
+// for log tags, so full bot tokens never end up in logs.
+func botIdentity(token string) string {
+	if len(token) <= 6 {
+		return token
+	}
+	return "..." + token[len(token)-6:]
+}
@@ -0,0 +1,13 @@
+package telexy
+
+import "testing"
+
+func TestBotIdentityRedactsToken(t *testing.T) {
+	got := botIdentity("123456:AAETotallySecretTokenValue")
+	if got == "123456:AAETotallySecretTokenValue" {
+		t.Fatal("botIdentity must not return the full token")
+	}
+	if len(got) > 10 {
+		t.Fatalf("expected a short suffix, got %q", got)
+	}
+}
@@ -0,0 +1,71 @@
+package telexy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// DoMultipart sends method as a multipart/form-data request, which the
+// Telegram Bot API requires for parameters that carry file content (e.g.
+// sendPhoto's "photo", sendDocument's "document"). fields holds the
+// ordinary string parameters and files holds the parameters whose value is
+// raw file content, keyed by form field name; both are merged into a
+// single multipart body. File readers are streamed into the request body
+// via an io.Pipe, so their full contents are never buffered in memory. If
+// the call succeeds, the response's "result" field is decoded with
+// consume, exactly as Do does.
+//
+// Unlike Do, DoMultipart does not retry transient failures: files is a map
+// of io.Reader, most of which (an *os.File mid-read, a network stream) can't
+// be safely replayed from the start. Callers needing retries should reopen
+// their readers and call DoMultipart again.
+func (c *Client) DoMultipart(ctx context.Context, method string, fields map[string]string, files map[string]io.Reader, consume func(*jsoniter.Iterator) error) error {
+	if c.dryRun && !c.readOnlyMeth[method] {
+		c.logger.Info("dry run: skipping mutating call", "method", method, "fields", fields)
+		return nil
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeMultipartBody(mw, fields, files))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.methodURL(method), pr)
+	if err != nil {
+		return fmt.Errorf("telexy: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("telexy: do request: %w", err)
+	}
+	defer drainAndClose(resp)
+
+	return readResponse(method, resp, consume)
+}
+
+func writeMultipartBody(mw *multipart.Writer, fields map[string]string, files map[string]io.Reader) error {
+	for name, value := range fields {
+		if err := mw.WriteField(name, value); err != nil {
+			return fmt.Errorf("telexy: write multipart field %q: %w", name, err)
+		}
+	}
+	for name, r := range files {
+		part, err := mw.CreateFormFile(name, name)
+		if err != nil {
+			return fmt.Errorf("telexy: create multipart file %q: %w", name, err)
+		}
+		if _, err := io.Copy(part, r); err != nil {
+			return fmt.Errorf("telexy: stream multipart file %q: %w", name, err)
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,81 @@
+package telexy
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDoMultipartSendsFieldsAndFiles(t *testing.T) {
+	var gotChatID, gotFileContent string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+			t.Errorf("unexpected Content-Type: %q (%v)", r.Header.Get("Content-Type"), err)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm: %v", err)
+		}
+		gotChatID = r.FormValue("chat_id")
+
+		file, _, err := r.FormFile("photo")
+		if err != nil {
+			t.Fatalf("FormFile: %v", err)
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			t.Fatalf("read uploaded file: %v", err)
+		}
+		gotFileContent = string(data)
+
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := map[string]string{"chat_id": "123"}
+	files := map[string]io.Reader{"photo": strings.NewReader("binary-image-bytes")}
+
+	if err := c.DoMultipart(context.Background(), "sendPhoto", fields, files, nil); err != nil {
+		t.Fatalf("DoMultipart: %v", err)
+	}
+
+	if gotChatID != "123" {
+		t.Errorf("chat_id = %q, want 123", gotChatID)
+	}
+	if gotFileContent != "binary-image-bytes" {
+		t.Errorf("file content = %q, want binary-image-bytes", gotFileContent)
+	}
+}
+
+func TestDoMultipartSkippedUnderDryRun(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), &ClientOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]io.Reader{"photo": strings.NewReader("data")}
+	if err := c.DoMultipart(context.Background(), "sendPhoto", nil, files, nil); err != nil {
+		t.Fatalf("DoMultipart under dry run returned error: %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no HTTP call under DryRun, got %d", calls)
+	}
+}
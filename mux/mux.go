@@ -0,0 +1,722 @@
+// Package mux routes decoded updates to subscribers based on per-subscription
+// matching criteria such as commands. Dispatch fans updates out across a
+// small pool of worker goroutines, each owning a shard of the subscriptions,
+// so that one slow matcher doesn't stall delivery to the rest.
+package mux
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/renbou/telexy/api"
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// SubscriptionOpts configures how a subscription matches incoming updates.
+// An update matches if it satisfies at least one configured criterion; a
+// SubscriptionOpts with nothing set matches every update.
+type SubscriptionOpts struct {
+	// Commands, if non-empty, restricts the subscription to messages whose
+	// text begins with one of these commands (e.g. "/start").
+	Commands []string
+
+	// Entities, if non-empty, restricts the subscription to messages
+	// containing at least one entity of one of these types (e.g.
+	// "mention", "hashtag", "url", "bot_command"). This generalizes
+	// command matching to any entity Telegram annotates in message text.
+	Entities []string
+
+	// CommandPatterns, if non-empty, restricts the subscription to
+	// messages whose command token (extracted the same way Commands is
+	// matched, including honoring WithEntityCommands) matches at least
+	// one of these compiled patterns. It's checked after Commands and
+	// Aliases fail to match exactly, so an exact entry in Commands always
+	// wins over a pattern that would also match it; MatchedUpdate.Command
+	// reports the literal command token that matched the pattern, since
+	// a pattern has no single canonical spelling the way an alias does.
+	CommandPatterns []*regexp.Regexp
+
+	// CallbackPrefixes, if non-empty, restricts the subscription to
+	// callback_query updates whose data (decoded into Content.Text)
+	// begins with one of these prefixes, e.g. "page:" matching the data
+	// a paginated inline keyboard sends ("page:2", "page:3", ...). It has
+	// no effect on update types other than callback_query.
+	CallbackPrefixes []string
+
+	// InlinePrefixes, if non-empty, restricts the subscription to
+	// inline_query updates whose query text (decoded into Content.Text)
+	// begins with one of these prefixes. It has no effect on update
+	// types other than inline_query.
+	InlinePrefixes []string
+
+	// Aliases maps a canonical command to the additional spellings that
+	// should also trigger it, e.g. {"/help": {"/h", "/?"}}. A match via an
+	// alias is reported as its canonical command in MatchedUpdate.Command,
+	// so handler code can switch on the canonical spelling instead of
+	// checking which alias was used. The canonical command doesn't need
+	// to also appear in Commands.
+	Aliases map[string][]string
+
+	// Replay, if positive, delivers up to this many of the most recent
+	// matching updates already held in the Mux's replay buffer (see
+	// WithReplayBuffer) into the new subscription's stream at subscribe
+	// time, best-effort. It has no effect if the Mux has no replay buffer.
+	Replay int
+
+	// Priority breaks ties between overlapping subscriptions when the Mux
+	// was created WithFirstMatchMode: for a given update, only the
+	// matching subscription with the highest Priority receives it, ties
+	// broken in favor of the earlier-created subscription. Priority is
+	// ignored in the default fan-out mode, where every matching
+	// subscription receives its own copy.
+	Priority int
+
+	// Overflow controls what happens when this subscription's channel is
+	// full at delivery time. The zero value, OverflowDropNewest, discards
+	// the update that doesn't fit and is the longstanding default
+	// behavior. See OverflowPolicy for the alternatives.
+	Overflow OverflowPolicy
+
+	// NotifyDrops opts this subscription into receiving a notification,
+	// via Subscription.Errors, whenever an update intended for it is
+	// dropped due to Overflow. It's opt-in because most subscribers have
+	// no use for per-event detail beyond the cumulative Subscription.Dropped
+	// counter, and allocating and draining another channel per
+	// subscription isn't free.
+	NotifyDrops bool
+}
+
+// OverflowPolicy controls delivery when a subscription's channel is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest discards the update being delivered, leaving the
+	// channel's existing backlog untouched. This is the zero value, so
+	// subscriptions that don't set Overflow keep the original behavior.
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest discards the oldest queued update to make room
+	// for the new one, favoring recency over completeness.
+	OverflowDropOldest
+
+	// OverflowBlock applies backpressure: delivery waits for the
+	// subscription to make room. Because a worker matches and delivers to
+	// its subscriptions one at a time (see worker.run), a blocked
+	// OverflowBlock subscription stalls delivery to every other
+	// subscription sharing its worker until it drains. Use it only when
+	// that subscriber must never miss an update and its worker shard can
+	// tolerate the stall.
+	OverflowBlock
+)
+
+// MatchedUpdate wraps a delivered update with the Mux's matching metadata.
+type MatchedUpdate struct {
+	api.Update
+
+	// Command is the canonical command that matched, set only when the
+	// subscription matched via Commands or Aliases. If the match came via
+	// an alias, Command holds the canonical spelling rather than the
+	// alias that actually appeared in the message.
+	Command string
+}
+
+// Subscription is a handle to an active subscription on a Mux. Unsubscribe
+// tears it down asynchronously; the definitive signal that teardown
+// completed is the channel returned by Updates being closed.
+type Subscription struct {
+	id   uint64
+	opts SubscriptionOpts
+	ch   chan MatchedUpdate
+	errc chan error // nil unless opts.NotifyDrops
+
+	worker  int
+	dropped atomic.Uint64
+}
+
+// Updates returns the channel on which matching updates are delivered. The
+// channel is closed once the subscription has been fully torn down by its
+// owning worker.
+func (s *Subscription) Updates() <-chan MatchedUpdate { return s.ch }
+
+// Errors returns the channel on which this subscription is notified of
+// dropped updates, if it was created with SubscriptionOpts.NotifyDrops.
+// Otherwise it returns nil, which a caller can still safely range or select
+// over: a nil channel simply never has anything to receive. A notification
+// is best-effort, same as the drop itself; see Subscription.Dropped for the
+// cumulative count, which never misses an event.
+func (s *Subscription) Errors() <-chan error { return s.errc }
+
+// Dropped returns the number of updates dropped for this subscription due
+// to its channel being full, under OverflowDropNewest or OverflowDropOldest.
+// It's always zero for OverflowBlock, which never drops.
+func (s *Subscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Mux routes updates to subscribers based on each subscription's
+// SubscriptionOpts. The zero value is not usable; use New.
+type Mux struct {
+	entityCommands bool
+	replayCap      int
+	firstMatch     bool
+	botUsername    string
+	assign         func(id uint64, numWorkers int) int
+	logger         tlxlog.Logger
+
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[uint64]*Subscription
+	replay []api.Update // oldest first, length bounded by replayCap
+
+	workers []*worker
+}
+
+// Option configures a Mux at construction time.
+type Option func(*Mux)
+
+// WithEntityCommands makes the Mux match SubscriptionOpts.Commands against
+// the message's "bot_command" entity instead of scanning the raw text for a
+// leading "/". This correctly handles commands that aren't at text offset
+// 0 and commands containing non-ASCII text, which plain prefix scanning
+// gets wrong.
+func WithEntityCommands() Option {
+	return func(m *Mux) { m.entityCommands = true }
+}
+
+// WithReplayBuffer makes the Mux retain up to n of the most recently
+// dispatched updates, so subscriptions created with SubscriptionOpts.Replay
+// can receive a bit of history instead of only updates arriving after they
+// subscribed. n bounds the memory strictly; replay is best-effort.
+func WithReplayBuffer(n int) Option {
+	return func(m *Mux) {
+		if n > 0 {
+			m.replayCap = n
+		}
+	}
+}
+
+// WithWorkers sets the number of worker goroutines subscriptions are
+// sharded across. It defaults to runtime.GOMAXPROCS(0).
+func WithWorkers(n int) Option {
+	return func(m *Mux) {
+		if n > 0 {
+			m.workers = make([]*worker, n)
+		}
+	}
+}
+
+// WithDeterministicAssignment pins subscription-to-worker assignment to
+// id % numWorkers instead of the default hash-based spread, given a fixed
+// seed. This doesn't affect production behavior beyond making the
+// assignment reproducible; it exists so tests can reproduce specific
+// subscriber/worker interleavings (ordering, unsubscribe-confirmation
+// timing) deterministically.
+func WithDeterministicAssignment(seed uint64) Option {
+	return func(m *Mux) {
+		m.assign = func(id uint64, numWorkers int) int {
+			return int((id + seed) % uint64(numWorkers))
+		}
+	}
+}
+
+// WithFirstMatchMode makes the Mux deliver each update to at most one
+// matching subscription instead of fanning it out to every match: the
+// highest-Priority match wins, ties broken in favor of the earlier-created
+// subscription. This is for cases like overlapping prefix and exact command
+// subscriptions, where exactly one handler should see a given update.
+//
+// Evaluating a single global winner requires comparing every subscription
+// under one lock, so WithFirstMatchMode trades away the worker-sharded
+// parallelism of the default fan-out mode: Dispatch does the match
+// evaluation itself rather than handing the update to each worker. For a
+// Mux with many subscriptions or slow matchers, this is less parallel than
+// fan-out mode.
+func WithFirstMatchMode() Option {
+	return func(m *Mux) { m.firstMatch = true }
+}
+
+// WithBotUsername tells the Mux its own bot's username (without the
+// leading "@"), so a command addressed to a different bot in a group chat
+// ("/start@otherbot") doesn't match this Mux's subscriptions. Without it,
+// the username suffix is ignored and "/start@anything" matches the same as
+// plain "/start", which is the original, username-unaware behavior.
+func WithBotUsername(username string) Option {
+	return func(m *Mux) { m.botUsername = username }
+}
+
+// WithLogger makes the Mux tag its logs with component "mux" and send them
+// to logger instead of discarding them.
+func WithLogger(logger tlxlog.Logger) Option {
+	return func(m *Mux) {
+		if logger != nil {
+			m.logger = logger
+		}
+	}
+}
+
+func defaultAssign(id uint64, numWorkers int) int {
+	h := fnv.New32a()
+	var b [8]byte
+	for i := range b {
+		b[i] = byte(id >> (8 * i))
+	}
+	h.Write(b[:])
+	return int(h.Sum32()) % numWorkers
+}
+
+// New creates a Mux and starts its worker pool.
+func New(opts ...Option) *Mux {
+	m := &Mux{subs: make(map[uint64]*Subscription), assign: defaultAssign, logger: tlxlog.Nop}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.logger = m.logger.WithValues("component", tlxlog.ComponentMux)
+	if m.workers == nil {
+		m.workers = make([]*worker, runtime.GOMAXPROCS(0))
+	}
+	for i := range m.workers {
+		m.workers[i] = newWorker(m)
+		go m.workers[i].run()
+	}
+	return m
+}
+
+// Subscribe registers a new subscription matching opts and returns a handle
+// to it.
+func (m *Mux) Subscribe(opts SubscriptionOpts) *Subscription {
+	m.mu.Lock()
+	m.nextID++
+	sub := &Subscription{id: m.nextID, opts: opts, ch: make(chan MatchedUpdate, 1)}
+	if opts.NotifyDrops {
+		sub.errc = make(chan error, 1)
+	}
+	sub.worker = m.assign(sub.id, len(m.workers))
+	m.subs[sub.id] = sub
+
+	var replay []api.Update
+	if opts.Replay > 0 {
+		n := opts.Replay
+		if n > len(m.replay) {
+			n = len(m.replay)
+		}
+		replay = append(replay, m.replay[len(m.replay)-n:]...)
+	}
+	m.mu.Unlock()
+
+	m.workers[sub.worker].subCh <- sub
+
+	for _, u := range replay {
+		if cmd, ok := m.match(sub.opts, u); ok {
+			select {
+			case sub.ch <- MatchedUpdate{Update: u, Command: cmd}:
+			default:
+				// Best-effort: don't block Subscribe if the channel
+				// fills up during replay.
+			}
+		}
+	}
+
+	return sub
+}
+
+// Unsubscribe asynchronously removes sub from the Mux. Its owning worker
+// confirms teardown by closing sub's update channel.
+func (m *Mux) Unsubscribe(sub *Subscription) {
+	m.mu.Lock()
+	if _, ok := m.subs[sub.id]; !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.subs, sub.id)
+	m.mu.Unlock()
+
+	m.workers[sub.worker].unsubCh <- sub
+}
+
+// IsActive reports whether sub is still registered on m. Because
+// Unsubscribe's teardown is asynchronous (the owning worker must process it
+// before delivery truly stops), IsActive can report true briefly after
+// Unsubscribe returns. The only definitive signal that teardown has
+// completed is sub.Updates() being closed; IsActive is best used for
+// best-effort diagnostics, not for synchronizing with teardown.
+func (m *Mux) IsActive(sub *Subscription) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.subs[sub.id]
+	return ok
+}
+
+// SubscriptionCount returns the number of currently active subscriptions,
+// for diagnostics (e.g. a /debug endpoint reporting mux health).
+func (m *Mux) SubscriptionCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.subs)
+}
+
+// MostBackedUpSubscription returns the SubscriptionInfo of whichever
+// active subscription currently has the highest Backlog/Capacity fill
+// ratio, for a quick "is anyone falling behind" check without having to
+// scan the full Subscriptions snapshot. ok is false if there are no
+// active subscriptions.
+func (m *Mux) MostBackedUpSubscription() (info SubscriptionInfo, ok bool) {
+	infos := m.Subscriptions()
+	if len(infos) == 0 {
+		return SubscriptionInfo{}, false
+	}
+
+	worst := infos[0]
+	for _, candidate := range infos[1:] {
+		if fillRatio(candidate) > fillRatio(worst) {
+			worst = candidate
+		}
+	}
+	return worst, true
+}
+
+func fillRatio(info SubscriptionInfo) float64 {
+	if info.Capacity == 0 {
+		return 0
+	}
+	return float64(info.Backlog) / float64(info.Capacity)
+}
+
+// Len returns the number of currently active subscriptions. It's an alias
+// for SubscriptionCount, kept short for callers that just want a health
+// check (e.g. "did every expected handler actually subscribe").
+//
+// Mux has no Closed method: unlike a combinator that owns and drains a
+// source Stream, a Mux is driven by external calls to Dispatch and has no
+// lifecycle of its own to report as closed or open.
+func (m *Mux) Len() int {
+	return m.SubscriptionCount()
+}
+
+// SubscriptionOptsSnapshot returns the SubscriptionOpts of every currently
+// active subscription, in no particular order, so routing can be rebuilt on
+// a fresh Mux (e.g. after a config reload that swaps the underlying
+// streamer). It's a point-in-time snapshot, consistent as of one read of
+// m's subscription map; subscriptions created or torn down concurrently
+// aren't reflected. Pair it with SubscribeMany on the new Mux.
+func (m *Mux) SubscriptionOptsSnapshot() []SubscriptionOpts {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]SubscriptionOpts, 0, len(m.subs))
+	for _, sub := range m.subs {
+		out = append(out, sub.opts)
+	}
+	return out
+}
+
+// SubscriptionInfo is a read-only snapshot of one active subscription, for
+// admin/debugging endpoints that need to show "why isn't my handler
+// firing" without exposing *Subscription itself (and with it, the ability
+// to read or close its channel).
+type SubscriptionInfo struct {
+	// ID identifies the subscription for correlating with logs; it has no
+	// meaning outside of this Mux.
+	ID uint64
+
+	Commands         []string
+	Entities         []string
+	CallbackPrefixes []string
+	InlinePrefixes   []string
+
+	// CommandPatternCount is the number of compiled CommandPatterns
+	// configured on the subscription. The patterns themselves aren't
+	// exposed, since *regexp.Regexp carries no safe read-only view and a
+	// debugging endpoint only needs to know they're there.
+	CommandPatternCount int
+
+	Dropped uint64
+
+	// Backlog and Capacity are a momentary snapshot of len(sub.ch) and
+	// cap(sub.ch): how many matched updates are currently buffered for
+	// this subscription, and how many it can hold before its
+	// SubscriptionOpts.Overflow policy kicks in. A consumer reading this
+	// repeatedly to decide whether to spawn more handler goroutines
+	// should treat it as a noisy instantaneous reading, not a rate.
+	Backlog  int
+	Capacity int
+}
+
+// Subscriptions returns a snapshot of every currently active subscription.
+// It's a point-in-time read of m's subscription map; subscriptions created
+// or torn down concurrently aren't reflected.
+func (m *Mux) Subscriptions() []SubscriptionInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]SubscriptionInfo, 0, len(m.subs))
+	for _, sub := range m.subs {
+		out = append(out, SubscriptionInfo{
+			ID:                  sub.id,
+			Commands:            sub.opts.Commands,
+			Entities:            sub.opts.Entities,
+			CallbackPrefixes:    sub.opts.CallbackPrefixes,
+			InlinePrefixes:      sub.opts.InlinePrefixes,
+			CommandPatternCount: len(sub.opts.CommandPatterns),
+			Dropped:             sub.dropped.Load(),
+			Backlog:             len(sub.ch),
+			Capacity:            cap(sub.ch),
+		})
+	}
+	return out
+}
+
+// SubscribeMany subscribes every entry in opts, returning one Subscription
+// per entry in the same order. It's the counterpart to
+// SubscriptionOptsSnapshot for rebuilding a Mux's routing from a previous
+// snapshot.
+func (m *Mux) SubscribeMany(opts []SubscriptionOpts) []*Subscription {
+	subs := make([]*Subscription, len(opts))
+	for i, o := range opts {
+		subs[i] = m.Subscribe(o)
+	}
+	return subs
+}
+
+// Dispatch routes u to every subscription whose opts match it, via each
+// subscription's owning worker. If the Mux was created WithFirstMatchMode,
+// it instead routes u to only the highest-Priority match; see
+// WithFirstMatchMode.
+func (m *Mux) Dispatch(u api.Update) {
+	m.mu.Lock()
+	if m.replayCap > 0 {
+		m.replay = append(m.replay, u)
+		if len(m.replay) > m.replayCap {
+			m.replay = m.replay[len(m.replay)-m.replayCap:]
+		}
+	}
+	if m.firstMatch {
+		m.dispatchFirstMatchLocked(u)
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	for _, w := range m.workers {
+		w.dispatchCh <- u
+	}
+}
+
+// dispatchFirstMatchLocked finds the highest-Priority subscription matching
+// u (ties broken by lowest id, i.e. earliest created) and delivers to it
+// alone. It must be called with m.mu held.
+func (m *Mux) dispatchFirstMatchLocked(u api.Update) {
+	var winner *Subscription
+	var winnerCmd string
+	for _, sub := range m.subs {
+		cmd, ok := m.match(sub.opts, u)
+		if !ok {
+			continue
+		}
+		if winner == nil ||
+			sub.opts.Priority > winner.opts.Priority ||
+			(sub.opts.Priority == winner.opts.Priority && sub.id < winner.id) {
+			winner = sub
+			winnerCmd = cmd
+		}
+	}
+	if winner == nil {
+		return
+	}
+	deliver(winner, MatchedUpdate{Update: u, Command: winnerCmd}, m.logger)
+}
+
+// deliver sends mu to sub.ch according to sub.opts.Overflow, logging and
+// counting a drop if the channel is full and the policy isn't OverflowBlock.
+// If sub was created with NotifyDrops, a drop is also reported on
+// sub.Errors, best-effort.
+func deliver(sub *Subscription, mu MatchedUpdate, logger tlxlog.Logger) {
+	switch sub.opts.Overflow {
+	case OverflowBlock:
+		sub.ch <- mu
+	case OverflowDropOldest:
+		select {
+		case sub.ch <- mu:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- mu:
+			default:
+				sub.dropped.Add(1)
+				logger.Error("dropped update: subscription channel full", "subscription", sub.id, "update", mu.ID)
+				notifyDrop(sub, mu.ID)
+			}
+		}
+	default: // OverflowDropNewest
+		select {
+		case sub.ch <- mu:
+		default:
+			sub.dropped.Add(1)
+			logger.Error("dropped update: subscription channel full", "subscription", sub.id, "update", mu.ID)
+			notifyDrop(sub, mu.ID)
+		}
+	}
+}
+
+// notifyDrop reports a dropped update on sub.Errors, if sub was created
+// with NotifyDrops. It never blocks: a full or absent notification channel
+// simply means the notification is lost, same as the drop it's reporting.
+func notifyDrop(sub *Subscription, updateID int64) {
+	if sub.errc == nil {
+		return
+	}
+	select {
+	case sub.errc <- fmt.Errorf("mux: dropped update %d: subscription channel full", updateID):
+	default:
+	}
+}
+
+// match reports whether u satisfies opts, returning the canonical command
+// that matched (if any) alongside the result.
+func (m *Mux) match(opts SubscriptionOpts, u api.Update) (string, bool) {
+	if len(opts.Commands) == 0 && len(opts.Aliases) == 0 && len(opts.CommandPatterns) == 0 &&
+		len(opts.Entities) == 0 && len(opts.CallbackPrefixes) == 0 && len(opts.InlinePrefixes) == 0 {
+		return "", true
+	}
+	if cmd, ok := m.matchCommand(opts, u); ok {
+		return cmd, true
+	}
+	for _, want := range opts.Entities {
+		if hasEntityType(u.Content.Entities, want) {
+			return "", true
+		}
+	}
+	if u.Type == api.UpdateTypeCallbackQuery && hasPrefix(opts.CallbackPrefixes, u.Content.Text) {
+		return "", true
+	}
+	if u.Type == api.UpdateTypeInlineQuery && hasPrefix(opts.InlinePrefixes, u.Content.Text) {
+		return "", true
+	}
+	return "", false
+}
+
+// hasPrefix reports whether text begins with any of prefixes.
+func hasPrefix(prefixes []string, text string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Mux) matchCommand(opts SubscriptionOpts, u api.Update) (string, bool) {
+	if len(opts.Commands) == 0 && len(opts.Aliases) == 0 && len(opts.CommandPatterns) == 0 {
+		return "", false
+	}
+
+	var token string
+	var ok bool
+	if m.entityCommands {
+		token, ok = commandFromEntities(u.Content)
+	} else {
+		token, ok = extractLeadingCommand(u.Content.Text)
+	}
+	if !ok {
+		return "", false
+	}
+
+	cmd, username := splitCommandUsername(token)
+	if username != "" && m.botUsername != "" && !strings.EqualFold(username, m.botUsername) {
+		// "/start@otherbot" was addressed to a different bot in this
+		// group chat; don't let our own subscriptions react to it.
+		return "", false
+	}
+
+	if canon, ok := canonicalCommand(opts, cmd); ok {
+		return canon, true
+	}
+	return matchCommandPattern(opts, cmd)
+}
+
+// splitCommandUsername splits a command token like "/start@mybot" into its
+// command ("/start") and username ("mybot") parts. username is empty if
+// token has no "@" suffix.
+func splitCommandUsername(token string) (cmd, username string) {
+	if i := strings.IndexByte(token, '@'); i >= 0 {
+		return token[:i], token[i+1:]
+	}
+	return token, ""
+}
+
+// matchCommandPattern reports whether cmd (the extracted command token)
+// satisfies at least one of opts.CommandPatterns, returning cmd itself as
+// the matched command: unlike Aliases, a regexp has no single canonical
+// spelling to report in its place.
+func matchCommandPattern(opts SubscriptionOpts, cmd string) (string, bool) {
+	for _, p := range opts.CommandPatterns {
+		if p.MatchString(cmd) {
+			return cmd, true
+		}
+	}
+	return "", false
+}
+
+// extractLeadingCommand pulls the leading "/command" token off text, for
+// CommandPatterns matching in the non-WithEntityCommands case, where there's
+// no bot_command entity to consult.
+func extractLeadingCommand(text string) (string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", false
+	}
+	if i := strings.IndexAny(text, " \n\t"); i >= 0 {
+		return text[:i], true
+	}
+	return text, true
+}
+
+// canonicalCommand reports the canonical command cmd matches exactly,
+// either directly via opts.Commands or via opts.Aliases.
+func canonicalCommand(opts SubscriptionOpts, cmd string) (string, bool) {
+	for _, want := range opts.Commands {
+		if cmd == want {
+			return cmd, true
+		}
+	}
+	for canonical, aliases := range opts.Aliases {
+		if cmd == canonical {
+			return canonical, true
+		}
+		for _, alias := range aliases {
+			if cmd == alias {
+				return canonical, true
+			}
+		}
+	}
+	return "", false
+}
+
+// commandFromEntities returns the exact text of c's "bot_command" entity,
+// if it has one, rather than assuming the command sits at text offset 0.
+func commandFromEntities(c api.Content) (string, bool) {
+	for _, e := range c.Entities {
+		if e.Type != "bot_command" {
+			continue
+		}
+		if e.Offset < 0 || e.Length < 0 || e.Offset+e.Length > len(c.Text) {
+			continue
+		}
+		return c.Text[e.Offset : e.Offset+e.Length], true
+	}
+	return "", false
+}
+
+func hasEntityType(entities []api.MessageEntity, typ string) bool {
+	for _, e := range entities {
+		if e.Type == typ {
+			return true
+		}
+	}
+	return false
+}
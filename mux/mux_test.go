@@ -0,0 +1,620 @@
+package mux
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/api"
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// recordingLogger is a minimal tlxlog.Logger that records every message
+// logged under a given component, for asserting Mux tags its logs
+// correctly.
+type recordingLogger struct {
+	shared    *recordingLoggerState
+	component string
+}
+
+type recordingLoggerState struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{shared: &recordingLoggerState{messages: make(map[string][]string)}}
+}
+
+func (l *recordingLogger) Info(msg string, kv ...any)  { l.record(msg) }
+func (l *recordingLogger) Error(msg string, kv ...any) { l.record(msg) }
+
+func (l *recordingLogger) record(msg string) {
+	l.shared.mu.Lock()
+	defer l.shared.mu.Unlock()
+	l.shared.messages[l.component] = append(l.shared.messages[l.component], msg)
+}
+
+func (l *recordingLogger) WithValues(kv ...any) tlxlog.Logger {
+	component := l.component
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] == "component" {
+			component, _ = kv[i+1].(string)
+		}
+	}
+	return &recordingLogger{shared: l.shared, component: component}
+}
+
+func (l *recordingLogger) componentMessages(component string) []string {
+	l.shared.mu.Lock()
+	defer l.shared.mu.Unlock()
+	return l.shared.messages[component]
+}
+
+const testTimeout = time.Second
+
+func expectUpdate(t *testing.T, sub *Subscription) MatchedUpdate {
+	t.Helper()
+	select {
+	case u := <-sub.Updates():
+		return u
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for update")
+		return MatchedUpdate{}
+	}
+}
+
+func expectNoUpdate(t *testing.T, sub *Subscription) {
+	t.Helper()
+	select {
+	case u := <-sub.Updates():
+		t.Fatalf("unexpected update: %+v", u)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMuxDispatchMatchesCommand(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/help"}})
+	expectNoUpdate(t, sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start now"}})
+	if u := expectUpdate(t, sub); u.Content.Text != "/start now" {
+		t.Fatalf("got %q", u.Content.Text)
+	}
+}
+
+func TestMuxDispatchMatchesEntityType(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{Entities: []string{"mention"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{
+		Text:     "check out #golang",
+		Entities: []api.MessageEntity{{Type: "hashtag", Offset: 10, Length: 7}},
+	}})
+	expectNoUpdate(t, sub)
+
+	m.Dispatch(api.Update{Content: api.Content{
+		Text:     "hey @someone",
+		Entities: []api.MessageEntity{{Type: "mention", Offset: 4, Length: 8}},
+	}})
+	expectUpdate(t, sub)
+}
+
+func TestMuxEntityCommandMatchesNonLeadingCommand(t *testing.T) {
+	m := New(WithEntityCommands())
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	defer m.Unsubscribe(sub)
+
+	// The command isn't at offset 0; plain prefix scanning would miss it.
+	text := "hey bot /start"
+	m.Dispatch(api.Update{Content: api.Content{
+		Text:     text,
+		Entities: []api.MessageEntity{{Type: "bot_command", Offset: 8, Length: 6}},
+	}})
+	expectUpdate(t, sub)
+}
+
+func TestMuxReplayBufferDeliversRecentHistory(t *testing.T) {
+	m := New(WithReplayBuffer(2))
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start one"}})
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start two"}})
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start three"}})
+
+	// Give the dispatches a moment to land in the replay buffer before a
+	// new subscriber reads it back.
+	time.Sleep(50 * time.Millisecond)
+
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}, Replay: 1})
+	defer m.Unsubscribe(sub)
+
+	if u := expectUpdate(t, sub); u.Content.Text != "/start three" {
+		t.Fatalf("got %q, want the most recent replayed update", u.Content.Text)
+	}
+}
+
+func TestMuxDeterministicAssignment(t *testing.T) {
+	m1 := New(WithWorkers(4), WithDeterministicAssignment(7))
+	m2 := New(WithWorkers(4), WithDeterministicAssignment(7))
+
+	var subs1, subs2 []*Subscription
+	for i := 0; i < 8; i++ {
+		subs1 = append(subs1, m1.Subscribe(SubscriptionOpts{}))
+		subs2 = append(subs2, m2.Subscribe(SubscriptionOpts{}))
+	}
+	for i := range subs1 {
+		if subs1[i].worker != subs2[i].worker {
+			t.Fatalf("subscription %d assigned to different workers: %d vs %d", i, subs1[i].worker, subs2[i].worker)
+		}
+	}
+}
+
+func TestMuxIsActiveAndUnsubscribeTeardown(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{})
+
+	if !m.IsActive(sub) {
+		t.Fatal("expected subscription to be active right after Subscribe")
+	}
+
+	m.Unsubscribe(sub)
+	if m.IsActive(sub) {
+		t.Fatal("expected subscription to no longer be registered after Unsubscribe")
+	}
+
+	// The definitive signal that teardown completed is the channel closing.
+	select {
+	case _, ok := <-sub.Updates():
+		if ok {
+			t.Fatal("expected update channel to be closed after teardown")
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for update channel to close")
+	}
+}
+
+func TestMuxUnsubscribeIsIdempotent(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{})
+
+	m.Unsubscribe(sub)
+
+	// A second Unsubscribe on the same handle must be a safe no-op, not a
+	// double-close panic: m.subs no longer has sub's id after the first
+	// call, so the second call returns before ever touching unsubCh again.
+	m.Unsubscribe(sub)
+
+	select {
+	case _, ok := <-sub.Updates():
+		if ok {
+			t.Fatal("expected update channel to be closed after teardown")
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for update channel to close")
+	}
+}
+
+func TestMuxAliasMatchesReportCanonicalCommand(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{Aliases: map[string][]string{"/help": {"/h", "/?"}}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/h"}})
+	if u := expectUpdate(t, sub); u.Command != "/help" {
+		t.Fatalf("got Command %q, want /help", u.Command)
+	}
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/other"}})
+	expectNoUpdate(t, sub)
+}
+
+func TestMuxLogsDroppedUpdatesAsComponentMux(t *testing.T) {
+	logger := newRecordingLogger()
+	m := New(WithLogger(logger), WithWorkers(1))
+	sub := m.Subscribe(SubscriptionOpts{})
+	defer m.Unsubscribe(sub)
+
+	// sub's channel has capacity 1; fill it, then force a second dispatch
+	// to be dropped.
+	m.Dispatch(api.Update{Content: api.Content{Text: "first"}})
+	m.Dispatch(api.Update{Content: api.Content{Text: "second"}})
+
+	deadline := time.After(testTimeout)
+	for {
+		if msgs := logger.componentMessages("mux"); len(msgs) > 0 {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for a dropped-update log entry tagged component=mux")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestMuxFirstMatchModeDeliversOnlyToHighestPriority(t *testing.T) {
+	m := New(WithFirstMatchMode())
+	low := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}, Priority: 0})
+	high := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}, Priority: 10})
+	defer m.Unsubscribe(low)
+	defer m.Unsubscribe(high)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start"}})
+
+	expectUpdate(t, high)
+	expectNoUpdate(t, low)
+}
+
+func TestMuxFirstMatchModeBreaksTiesByEarlierSubscription(t *testing.T) {
+	m := New(WithFirstMatchMode())
+	first := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	second := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	defer m.Unsubscribe(first)
+	defer m.Unsubscribe(second)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start"}})
+
+	expectUpdate(t, first)
+	expectNoUpdate(t, second)
+}
+
+func TestMuxSubscriptionOptsSnapshotRoundTripsThroughSubscribeMany(t *testing.T) {
+	m1 := New()
+	m1.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	m1.Subscribe(SubscriptionOpts{Entities: []string{"mention"}})
+
+	snapshot := m1.SubscriptionOptsSnapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("got %d snapshotted subscriptions, want 2", len(snapshot))
+	}
+
+	m2 := New()
+	subs := m2.SubscribeMany(snapshot)
+	if len(subs) != 2 {
+		t.Fatalf("got %d subscriptions from SubscribeMany, want 2", len(subs))
+	}
+
+	m2.Dispatch(api.Update{Content: api.Content{Text: "/start"}})
+
+	var delivered int
+	for _, sub := range subs {
+		select {
+		case <-sub.Updates():
+			delivered++
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	if delivered != 1 {
+		t.Fatalf("got %d subscriptions matched on rebuilt mux, want 1", delivered)
+	}
+}
+
+func TestMuxLenMatchesSubscriptionCount(t *testing.T) {
+	m := New()
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d, want 0 for a fresh Mux", m.Len())
+	}
+
+	sub := m.Subscribe(SubscriptionOpts{})
+	if m.Len() != m.SubscriptionCount() || m.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1 and to match SubscriptionCount()", m.Len())
+	}
+
+	m.Unsubscribe(sub)
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d, want 0 after Unsubscribe", m.Len())
+	}
+}
+
+func TestMuxSubscriptionsReturnsSnapshotOfActiveSubscriptions(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}, CallbackPrefixes: []string{"page:"}})
+	m.Subscribe(SubscriptionOpts{Entities: []string{"mention"}})
+
+	infos := m.Subscriptions()
+	if len(infos) != 2 {
+		t.Fatalf("got %d subscription infos, want 2", len(infos))
+	}
+
+	var found bool
+	for _, info := range infos {
+		if info.ID != sub.id {
+			continue
+		}
+		found = true
+		if len(info.Commands) != 1 || info.Commands[0] != "/start" {
+			t.Fatalf("Commands = %v, want [/start]", info.Commands)
+		}
+		if len(info.CallbackPrefixes) != 1 || info.CallbackPrefixes[0] != "page:" {
+			t.Fatalf("CallbackPrefixes = %v, want [page:]", info.CallbackPrefixes)
+		}
+	}
+	if !found {
+		t.Fatal("expected Subscriptions to include the first subscription's info")
+	}
+
+	m.Unsubscribe(sub)
+	// Mux.subs is updated synchronously by Unsubscribe, unlike channel
+	// teardown, so this doesn't need to wait for the owning worker.
+	if got := len(m.Subscriptions()); got != 1 {
+		t.Fatalf("got %d subscription infos after unsubscribe, want 1", got)
+	}
+}
+
+func TestMuxSubscriptionInfoReportsBacklog(t *testing.T) {
+	m := New(WithFirstMatchMode())
+	sub := m.Subscribe(SubscriptionOpts{Overflow: OverflowDropNewest})
+
+	m.Dispatch(api.Update{Content: api.Content{}})
+
+	var info SubscriptionInfo
+	for _, candidate := range m.Subscriptions() {
+		if candidate.ID == sub.id {
+			info = candidate
+		}
+	}
+	if info.Capacity != 1 {
+		t.Fatalf("Capacity = %d, want 1", info.Capacity)
+	}
+	if info.Backlog != 1 {
+		t.Fatalf("Backlog = %d, want 1 before the update is drained", info.Backlog)
+	}
+
+	<-sub.Updates()
+	if got := m.Subscriptions()[0].Backlog; got != 0 {
+		t.Fatalf("Backlog after drain = %d, want 0", got)
+	}
+}
+
+func TestMuxMostBackedUpSubscriptionPicksHighestFillRatio(t *testing.T) {
+	m := New(WithFirstMatchMode())
+	idle := m.Subscribe(SubscriptionOpts{Commands: []string{"/idle"}})
+	busy := m.Subscribe(SubscriptionOpts{Commands: []string{"/busy"}})
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/busy"}})
+
+	worst, ok := m.MostBackedUpSubscription()
+	if !ok {
+		t.Fatal("expected MostBackedUpSubscription to report a subscription")
+	}
+	if worst.ID != busy.id {
+		t.Fatalf("MostBackedUpSubscription = %d, want the busy subscription %d", worst.ID, busy.id)
+	}
+	_ = idle
+}
+
+func TestMuxEntityCommandAliasMatchesCanonical(t *testing.T) {
+	m := New(WithEntityCommands())
+	sub := m.Subscribe(SubscriptionOpts{Aliases: map[string][]string{"/help": {"/h"}}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{
+		Text:     "/h",
+		Entities: []api.MessageEntity{{Type: "bot_command", Offset: 0, Length: 2}},
+	}})
+	if u := expectUpdate(t, sub); u.Command != "/help" {
+		t.Fatalf("got Command %q, want /help", u.Command)
+	}
+}
+
+func TestMuxOverflowDropOldestKeepsMostRecent(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{Overflow: OverflowDropOldest})
+	defer m.Unsubscribe(sub)
+
+	// sub's channel has capacity 1; with OverflowDropOldest the second
+	// delivery should evict "first" and leave "second" queued. Going
+	// through Dispatch would race the worker goroutine's asynchronous
+	// delivery (it only hands the update off and returns), so this calls
+	// deliver directly to apply the overflow policy synchronously and
+	// deterministically; the policy itself doesn't depend on the worker.
+	deliver(sub, MatchedUpdate{Update: api.Update{Content: api.Content{Text: "first"}}}, tlxlog.Nop)
+	deliver(sub, MatchedUpdate{Update: api.Update{Content: api.Content{Text: "second"}}}, tlxlog.Nop)
+
+	select {
+	case u := <-sub.Updates():
+		if u.Content.Text != "second" {
+			t.Fatalf("got %q, want second", u.Content.Text)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for the surviving update")
+	}
+}
+
+func TestMuxOverflowBlockDeliversBothWithoutDropping(t *testing.T) {
+	m := New(WithWorkers(1))
+	sub := m.Subscribe(SubscriptionOpts{Overflow: OverflowBlock})
+	defer m.Unsubscribe(sub)
+
+	go func() {
+		m.Dispatch(api.Update{Content: api.Content{Text: "first"}})
+		m.Dispatch(api.Update{Content: api.Content{Text: "second"}})
+	}()
+
+	first := expectUpdate(t, sub)
+	second := expectUpdate(t, sub)
+	if first.Content.Text != "first" || second.Content.Text != "second" {
+		t.Fatalf("got %q, %q, want first, second", first.Content.Text, second.Content.Text)
+	}
+	if sub.Dropped() != 0 {
+		t.Fatalf("Dropped() = %d, want 0", sub.Dropped())
+	}
+}
+
+func TestMuxOverflowDropNewestCountsDrops(t *testing.T) {
+	m := New(WithWorkers(1))
+	sub := m.Subscribe(SubscriptionOpts{})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "first"}})
+	m.Dispatch(api.Update{Content: api.Content{Text: "second"}})
+
+	deadline := time.After(testTimeout)
+	for {
+		if sub.Dropped() == 1 {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("Dropped() = %d, want 1", sub.Dropped())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestMuxNotifyDropsReportsDropOnErrorsChannel(t *testing.T) {
+	m := New(WithWorkers(1))
+	sub := m.Subscribe(SubscriptionOpts{NotifyDrops: true})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "first"}})
+	m.Dispatch(api.Update{Content: api.Content{Text: "second"}})
+
+	select {
+	case err := <-sub.Errors():
+		if err == nil {
+			t.Fatal("got nil error, want a drop notification")
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for a drop notification")
+	}
+}
+
+func TestMuxWithoutNotifyDropsErrorsChannelIsNil(t *testing.T) {
+	m := New(WithWorkers(1))
+	sub := m.Subscribe(SubscriptionOpts{})
+	defer m.Unsubscribe(sub)
+
+	if sub.Errors() != nil {
+		t.Fatal("Errors() should be nil when NotifyDrops wasn't set")
+	}
+}
+
+func TestMuxCommandPatternsMatchDynamicSuffix(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{CommandPatterns: []*regexp.Regexp{regexp.MustCompile(`^/order_\d+$`)}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/order_12345"}})
+
+	if u := expectUpdate(t, sub); u.Command != "/order_12345" {
+		t.Fatalf("got Command %q, want /order_12345", u.Command)
+	}
+}
+
+func TestMuxCommandPatternsDoNotMatchUnrelatedText(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{CommandPatterns: []*regexp.Regexp{regexp.MustCompile(`^/order_\d+$`)}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start"}})
+
+	expectNoUpdate(t, sub)
+}
+
+func TestMuxExactCommandsTakePrecedenceOverPatterns(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{
+		Commands:        []string{"/order_0"},
+		CommandPatterns: []*regexp.Regexp{regexp.MustCompile(`^/order_\d+$`)},
+	})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/order_0"}})
+
+	if u := expectUpdate(t, sub); u.Command != "/order_0" {
+		t.Fatalf("got Command %q, want /order_0", u.Command)
+	}
+}
+
+func TestMuxCommandPatternsMatchViaEntities(t *testing.T) {
+	m := New(WithEntityCommands())
+	sub := m.Subscribe(SubscriptionOpts{CommandPatterns: []*regexp.Regexp{regexp.MustCompile(`^/order_\d+$`)}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{
+		Text:     "check /order_42 now",
+		Entities: []api.MessageEntity{{Type: "bot_command", Offset: 6, Length: 9}},
+	}})
+
+	if u := expectUpdate(t, sub); u.Command != "/order_42" {
+		t.Fatalf("got Command %q, want /order_42", u.Command)
+	}
+}
+
+func TestMuxBotUsernameRejectsCommandAddressedToOtherBot(t *testing.T) {
+	m := New(WithBotUsername("mybot"))
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start@otherbot"}})
+	expectNoUpdate(t, sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start@mybot"}})
+	expectUpdate(t, sub)
+}
+
+func TestMuxBotUsernameAllowsPlainCommandWithoutSuffix(t *testing.T) {
+	m := New(WithBotUsername("mybot"))
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start"}})
+	expectUpdate(t, sub)
+}
+
+func TestMuxWithoutBotUsernameIgnoresSuffix(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{Commands: []string{"/start"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{Content: api.Content{Text: "/start@anything"}})
+	expectUpdate(t, sub)
+}
+
+func TestMuxCallbackPrefixesMatchesCallbackData(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{CallbackPrefixes: []string{"page:"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{
+		UpdateInfo: api.UpdateInfo{Type: api.UpdateTypeCallbackQuery},
+		Content:    api.Content{Text: "page:2"},
+	})
+	expectUpdate(t, sub)
+
+	m.Dispatch(api.Update{
+		UpdateInfo: api.UpdateInfo{Type: api.UpdateTypeCallbackQuery},
+		Content:    api.Content{Text: "cancel"},
+	})
+	expectNoUpdate(t, sub)
+}
+
+func TestMuxInlinePrefixesMatchesInlineQueryText(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{InlinePrefixes: []string{"search:"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{
+		UpdateInfo: api.UpdateInfo{Type: api.UpdateTypeInlineQuery},
+		Content:    api.Content{Text: "search:cats"},
+	})
+	expectUpdate(t, sub)
+}
+
+func TestMuxCallbackPrefixesIgnoreOtherUpdateTypes(t *testing.T) {
+	m := New()
+	sub := m.Subscribe(SubscriptionOpts{CallbackPrefixes: []string{"page:"}})
+	defer m.Unsubscribe(sub)
+
+	m.Dispatch(api.Update{
+		UpdateInfo: api.UpdateInfo{Type: api.UpdateTypeMessage},
+		Content:    api.Content{Text: "page:2"},
+	})
+	expectNoUpdate(t, sub)
+}
@@ -0,0 +1,48 @@
+package mux
+
+import "github.com/renbou/telexy/api"
+
+// worker owns a shard of a Mux's subscriptions and serializes matching and
+// delivery for them, so a slow matcher on one shard can't stall another.
+type worker struct {
+	mux *Mux
+
+	subCh      chan *Subscription
+	unsubCh    chan *Subscription
+	dispatchCh chan api.Update
+
+	subs map[uint64]*Subscription
+}
+
+func newWorker(m *Mux) *worker {
+	return &worker{
+		mux:        m,
+		subCh:      make(chan *Subscription),
+		unsubCh:    make(chan *Subscription),
+		dispatchCh: make(chan api.Update, 16),
+		subs:       make(map[uint64]*Subscription),
+	}
+}
+
+func (w *worker) run() {
+	for {
+		select {
+		case sub := <-w.subCh:
+			w.subs[sub.id] = sub
+		case sub := <-w.unsubCh:
+			if _, ok := w.subs[sub.id]; ok {
+				delete(w.subs, sub.id)
+				close(sub.ch)
+				if sub.errc != nil {
+					close(sub.errc)
+				}
+			}
+		case u := <-w.dispatchCh:
+			for _, sub := range w.subs {
+				if cmd, ok := w.mux.match(sub.opts, u); ok {
+					deliver(sub, MatchedUpdate{Update: u, Command: cmd}, w.mux.logger)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package telexy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OffsetStore persists a long-poll streamer's offset across restarts, so a
+// crash doesn't force either re-reading the whole backlog from zero or
+// relying on Telegram's own redelivery timing. Load seeds the offset at
+// startup; Save is called after each batch advances it. Offsets use int64
+// rather than the plain int a Go API might default to, matching every
+// other ID-bearing value in this package, since a 32-bit int would
+// silently truncate update IDs on a 32-bit platform.
+type OffsetStore interface {
+	// Load returns the last persisted offset, or 0 if none has been saved
+	// yet (a first run).
+	Load() (int64, error)
+	Save(offset int64) error
+}
+
+// FileOffsetStore is an OffsetStore backed by a plain text file holding the
+// offset as a decimal integer.
+type FileOffsetStore struct {
+	path string
+}
+
+// NewFileOffsetStore creates a FileOffsetStore persisting to path.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+// Load reads the persisted offset, returning 0 (not an error) if path
+// doesn't exist yet.
+func (s *FileOffsetStore) Load() (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("telexy: parsing offset file %s: %w", s.path, err)
+	}
+	return offset, nil
+}
+
+// Save persists offset, replacing any previously saved value. It writes to
+// a temp file alongside path and renames it into place, so a crash
+// mid-write can't leave a truncated, unparseable offset file behind for
+// the next Load.
+func (s *FileOffsetStore) Save(offset int64) error {
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,58 @@
+package telexy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileOffsetStoreLoadReturnsZeroWhenMissing(t *testing.T) {
+	s := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+
+	offset, err := s.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if offset != 0 {
+		t.Fatalf("offset = %d, want 0", offset)
+	}
+}
+
+func TestFileOffsetStoreSaveThenLoadRoundTrips(t *testing.T) {
+	s := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+
+	if err := s.Save(42); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	offset, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if offset != 42 {
+		t.Fatalf("offset = %d, want 42", offset)
+	}
+
+	if err := s.Save(43); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	offset, err = s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if offset != 43 {
+		t.Fatalf("offset = %d, want 43 after overwrite", offset)
+	}
+}
+
+func TestFileOffsetStoreLoadRejectsCorruptContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offset")
+	s := NewFileOffsetStore(path)
+	if err := os.WriteFile(path, []byte("not-a-number"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Load(); err == nil {
+		t.Fatal("expected an error for corrupt offset file content")
+	}
+}
@@ -0,0 +1,68 @@
+package telexy
+
+// OffsetStrategy decides how far a long-poll streamer's getUpdates offset
+// may advance after a poll batch has been delivered, decoupling the
+// delivery guarantee from the polling loop itself. ids holds a batch's
+// update IDs in ascending order; delivered[i] reports whether ids[i] was
+// actually sent downstream (false only for updates after ctx was canceled
+// mid-batch). Advance returns the new offset to commit, or 0 to leave the
+// current offset unchanged.
+type OffsetStrategy interface {
+	Advance(ids []int64, delivered []bool) int64
+}
+
+// ImmediateOffsetStrategy advances to the last id in every batch as soon as
+// it's decoded, independent of whether delivery actually succeeded. This
+// gives the lowest latency and least redelivery, at the cost of being able
+// to lose an update if the process dies between decoding it and a consumer
+// handling it.
+var ImmediateOffsetStrategy OffsetStrategy = immediateOffsetStrategy{}
+
+type immediateOffsetStrategy struct{}
+
+func (immediateOffsetStrategy) Advance(ids []int64, delivered []bool) int64 {
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[len(ids)-1]
+}
+
+// AfterSendOffsetStrategy, the default, advances update-by-update: each id
+// is committed only once it was actually handed to the consumer channel.
+// It stops at the first undelivered update in a batch, so an update that
+// wasn't sent (and anything after it) is redelivered on the next poll.
+var AfterSendOffsetStrategy OffsetStrategy = afterSendOffsetStrategy{}
+
+type afterSendOffsetStrategy struct{}
+
+func (afterSendOffsetStrategy) Advance(ids []int64, delivered []bool) int64 {
+	var committed int64
+	for i, ok := range delivered {
+		if !ok {
+			break
+		}
+		committed = ids[i]
+	}
+	return committed
+}
+
+// BatchOffsetStrategy advances only once every update in the batch was
+// delivered, committing the whole batch atomically rather than
+// update-by-update. A single undelivered update causes the entire batch to
+// be redelivered on the next poll, trading more redelivery for a simpler
+// "all or nothing" guarantee.
+var BatchOffsetStrategy OffsetStrategy = batchOffsetStrategy{}
+
+type batchOffsetStrategy struct{}
+
+func (batchOffsetStrategy) Advance(ids []int64, delivered []bool) int64 {
+	for _, ok := range delivered {
+		if !ok {
+			return 0
+		}
+	}
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[len(ids)-1]
+}
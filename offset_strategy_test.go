@@ -0,0 +1,44 @@
+package telexy
+
+import "testing"
+
+func TestImmediateOffsetStrategyIgnoresDelivery(t *testing.T) {
+	got := ImmediateOffsetStrategy.Advance([]int64{1, 2, 3}, []bool{true, false, false})
+	if got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+}
+
+func TestAfterSendOffsetStrategyStopsAtFirstUndelivered(t *testing.T) {
+	got := AfterSendOffsetStrategy.Advance([]int64{1, 2, 3}, []bool{true, true, false})
+	if got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+}
+
+func TestAfterSendOffsetStrategyAdvancesFullyWhenAllDelivered(t *testing.T) {
+	got := AfterSendOffsetStrategy.Advance([]int64{1, 2, 3}, []bool{true, true, true})
+	if got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+}
+
+func TestBatchOffsetStrategyRequiresFullDelivery(t *testing.T) {
+	got := BatchOffsetStrategy.Advance([]int64{1, 2, 3}, []bool{true, true, false})
+	if got != 0 {
+		t.Fatalf("got %d, want 0", got)
+	}
+
+	got = BatchOffsetStrategy.Advance([]int64{1, 2, 3}, []bool{true, true, true})
+	if got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+}
+
+func TestOffsetStrategiesHandleEmptyBatch(t *testing.T) {
+	for _, s := range []OffsetStrategy{ImmediateOffsetStrategy, AfterSendOffsetStrategy, BatchOffsetStrategy} {
+		if got := s.Advance(nil, nil); got != 0 {
+			t.Fatalf("got %d, want 0", got)
+		}
+	}
+}
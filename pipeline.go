@@ -0,0 +1,132 @@
+package telexy
+
+import (
+	"context"
+
+	"github.com/renbou/telexy/mux"
+)
+
+// Pipeline owns the streamer -> mux wiring for a single bot and codifies the
+// only safe shutdown order: stop accepting new updates first, then let
+// whatever's already buffered drain through the mux before returning.
+// Getting this order wrong is what leads to "send on closed channel" panics
+// or updates silently dropped mid-shutdown.
+type Pipeline struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	streamer Streamer[Update]
+	mux      *mux.Mux
+
+	memLimiter *PipelineMemoryLimiter
+}
+
+// PipelineOption configures a Pipeline at construction time.
+type PipelineOption func(*Pipeline)
+
+// WithMemoryLimiter makes the Pipeline account each update's estimated
+// size against limiter before dispatching it, pausing the hand-off (and
+// transitively, polling) once the ceiling is reached. See
+// PipelineMemoryLimiter for exactly what it covers.
+func WithMemoryLimiter(limiter *PipelineMemoryLimiter) PipelineOption {
+	return func(p *Pipeline) { p.memLimiter = limiter }
+}
+
+// NewPipeline starts streamer and dispatches everything it produces into m,
+// until ctx is canceled or Shutdown is called.
+func NewPipeline(ctx context.Context, streamer Streamer[Update], m *mux.Mux, opts ...PipelineOption) *Pipeline {
+	streamCtx, cancel := context.WithCancel(ctx)
+	p := &Pipeline{cancel: cancel, done: make(chan struct{}), streamer: streamer, mux: m}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	go func() {
+		defer close(p.done)
+		updates, errs := streamer.Stream(streamCtx)
+		for updates != nil || errs != nil {
+			select {
+			case u, ok := <-updates:
+				if !ok {
+					updates = nil
+					continue
+				}
+				p.dispatch(streamCtx, m, u)
+			case _, ok := <-errs:
+				if !ok {
+					errs = nil
+				}
+			}
+		}
+	}()
+
+	return p
+}
+
+// dispatch hands u to m, first acquiring room for it from p.memLimiter if
+// one is configured. If ctx is canceled while waiting for room, u is
+// dropped; the pipeline's main loop will observe the cancellation and
+// exit on its own next iteration.
+func (p *Pipeline) dispatch(ctx context.Context, m *mux.Mux, u Update) {
+	if p.memLimiter == nil {
+		m.Dispatch(u)
+		return
+	}
+
+	size, err := p.memLimiter.Acquire(ctx, u)
+	if err != nil {
+		return
+	}
+	defer p.memLimiter.Release(size)
+
+	m.Dispatch(u)
+}
+
+// DebugInfo is a point-in-time diagnostic snapshot of a Pipeline, meant to
+// be served from a /debug endpoint so operators have a single place to
+// inspect a bot's health.
+type DebugInfo struct {
+	// Offset is the long-poll streamer's committed update_id, and
+	// HasOffset reports whether one was available: Offset is only
+	// meaningful for a long-poll streamer, so it's left zero with
+	// HasOffset false for any other Streamer[Update] implementation
+	// (e.g. a webhook streamer).
+	Offset    int64 `json:"offset,omitempty"`
+	HasOffset bool  `json:"has_offset"`
+
+	// Subscriptions is the number of currently active subscriptions on
+	// the Pipeline's Mux.
+	Subscriptions int `json:"subscriptions"`
+}
+
+// offsetReader is implemented by streamers that track a resumable
+// position, currently only *longPollStreamer. Debug reaches it via type
+// assertion rather than adding Offset to the Streamer interface, matching
+// how longPollStreamer already exposes WaitIdle as an unexported-type-only
+// extra.
+type offsetReader interface {
+	Offset() int64
+}
+
+// Debug returns a snapshot of p's current state, composing the streamer's
+// offset (if it tracks one) with the Mux's subscription count.
+func (p *Pipeline) Debug() DebugInfo {
+	info := DebugInfo{Subscriptions: p.mux.SubscriptionCount()}
+	if or, ok := p.streamer.(offsetReader); ok {
+		info.Offset = or.Offset()
+		info.HasOffset = true
+	}
+	return info
+}
+
+// Shutdown cancels the streamer's context and blocks until every update it
+// had already produced has finished draining through the mux, or ctx is
+// done first.
+func (p *Pipeline) Shutdown(ctx context.Context) error {
+	p.cancel()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/mux"
+)
+
+func TestPipelineShutdownDrainsBeforeReturning(t *testing.T) {
+	src := make(chan Update, 2)
+	src <- Update{Content: Content{Text: "/start one"}}
+	src <- Update{Content: Content{Text: "/start two"}}
+
+	m := mux.New()
+	sub := m.Subscribe(mux.SubscriptionOpts{Commands: []string{"/start"}})
+
+	streamer := ChannelStreamer[Update](src)
+	p := NewPipeline(context.Background(), streamer, m)
+	close(src)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	got := 0
+	for got < 2 {
+		select {
+		case <-sub.Updates():
+			got++
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for drained updates, got %d", got)
+		}
+	}
+}
+
+// TestPipelineShutdownDoesNotLeakGoroutines guards against Shutdown
+// returning while its draining goroutine (started by NewPipeline) is still
+// around. telexy has no dependency on go.uber.org/goleak, so this checks the
+// same thing goleak.VerifyNone would by comparing runtime.NumGoroutine
+// before and after, giving the draining goroutine a short grace period to
+// actually exit before failing.
+func TestPipelineShutdownDoesNotLeakGoroutines(t *testing.T) {
+	src := make(chan Update, 1)
+	src <- Update{Content: Content{Text: "/start"}}
+	close(src)
+
+	// m's own worker goroutines (see mux.New) have no lifecycle tied to the
+	// Pipeline and outlive it, so the baseline is taken after m exists:
+	// Shutdown should only account for the goroutine NewPipeline itself
+	// started to drain the streamer.
+	m := mux.New()
+	m.Subscribe(mux.SubscriptionOpts{Commands: []string{"/start"}})
+	before := runtime.NumGoroutine()
+
+	streamer := ChannelStreamer[Update](src)
+	p := NewPipeline(context.Background(), streamer, m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if after := runtime.NumGoroutine(); after <= before {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutine count = %d after Shutdown, want <= %d (pre-Pipeline)", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPipelineWithMemoryLimiterStillDeliversAllUpdates(t *testing.T) {
+	src := make(chan Update, 2)
+	src <- Update{Content: Content{Text: "/start one"}}
+	src <- Update{Content: Content{Text: "/start two"}}
+	close(src)
+
+	m := mux.New()
+	sub := m.Subscribe(mux.SubscriptionOpts{Commands: []string{"/start"}})
+
+	limiter := NewPipelineMemoryLimiter(1, func(Update) int64 { return 1 })
+	streamer := ChannelStreamer[Update](src)
+	p := NewPipeline(context.Background(), streamer, m, WithMemoryLimiter(limiter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	got := 0
+	for got < 2 {
+		select {
+		case <-sub.Updates():
+			got++
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for drained updates, got %d", got)
+		}
+	}
+	if limiter.Usage() != 0 {
+		t.Fatalf("limiter usage = %d after drain, want 0", limiter.Usage())
+	}
+}
+
+func TestPipelineDebugReportsSubscriptionCountWithNoOffsetForNonLongPollStreamer(t *testing.T) {
+	src := make(chan Update)
+	defer close(src)
+
+	m := mux.New()
+	m.Subscribe(mux.SubscriptionOpts{Commands: []string{"/start"}})
+	m.Subscribe(mux.SubscriptionOpts{Commands: []string{"/help"}})
+
+	streamer := ChannelStreamer[Update](src)
+	p := NewPipeline(context.Background(), streamer, m)
+	defer p.Shutdown(context.Background())
+
+	info := p.Debug()
+	if info.Subscriptions != 2 {
+		t.Fatalf("Subscriptions = %d, want 2", info.Subscriptions)
+	}
+	if info.HasOffset {
+		t.Fatalf("HasOffset = true, want false for a non-long-poll streamer")
+	}
+}
+
+func TestPipelineDebugReportsOffsetForLongPollStreamer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"text":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamer := NewLongPoll[Update](c, LongPollOptions[Update]{Decoder: AsUpdate})
+
+	m := mux.New()
+	p := NewPipeline(context.Background(), streamer, m)
+	defer p.Shutdown(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if info := p.Debug(); info.HasOffset && info.Offset == 7 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Debug() never reported offset 7, last = %+v", p.Debug())
+}
@@ -0,0 +1,82 @@
+package telexy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+)
+
+// NewReaderStreamer creates a Streamer that reads newline-delimited JSON
+// updates from r, decoding each line with decoder (AsUpdate for T ==
+// Update) and emitting it as soon as it's read, with no pacing. Each line
+// must be a single Telegram update object, the same shape getUpdates
+// returns one element of, not a whole getUpdates response.
+//
+// This is meant for local development: piping or pasting updates in lets
+// you exercise handler logic without a token, network access, or Telegram
+// itself. It's not meant for replaying production traffic at realistic
+// timing; use a dedicated replay mechanism for that instead.
+//
+// The returned Streamer closes both channels once ctx is canceled, r is
+// exhausted, or a line fails to decode. A read error other than io.EOF is
+// sent on the error channel before it closes.
+func NewReaderStreamer[T any](r io.Reader, decoder UpdateDecoder[T]) Streamer[T] {
+	return StreamerFunc[T](func(ctx context.Context) (<-chan T, <-chan error) {
+		out := make(chan T)
+		errc := make(chan error, 1)
+
+		go func() {
+			defer close(out)
+			defer close(errc)
+
+			scanner := bufio.NewScanner(r)
+			scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+			for scanner.Scan() {
+				if ctx.Err() != nil {
+					return
+				}
+
+				line := bytes.TrimSpace(scanner.Bytes())
+				if len(line) == 0 {
+					continue
+				}
+
+				it := json.BorrowIterator(line)
+				v, err := decoder(it)
+				json.ReturnIterator(it)
+				if err != nil {
+					select {
+					case errc <- err:
+					case <-ctx.Done():
+					}
+					return
+				}
+
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				select {
+				case errc <- err:
+				case <-ctx.Done():
+				}
+			}
+		}()
+
+		return out, errc
+	})
+}
+
+// NewStdinStreamer creates a Streamer reading newline-delimited JSON
+// updates from os.Stdin, for quickly exercising handler logic by pasting
+// or piping updates in a terminal. See NewReaderStreamer for the input
+// format and behavior.
+func NewStdinStreamer[T any](decoder UpdateDecoder[T]) Streamer[T] {
+	return NewReaderStreamer[T](os.Stdin, decoder)
+}
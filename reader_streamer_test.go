@@ -0,0 +1,86 @@
+package telexy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+func TestReaderStreamerDecodesEachLine(t *testing.T) {
+	r := strings.NewReader(`{"update_id":1,"message":{"text":"one"}}
+{"update_id":2,"message":{"text":"two"}}
+`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, errc := NewReaderStreamer[Update](r, AsUpdate).Stream(ctx)
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		select {
+		case u := <-out:
+			got = append(got, u.Content.Text)
+		case err := <-errc:
+			t.Fatalf("unexpected error: %v", err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for update")
+		}
+	}
+	if len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Fatalf("got %v, want [one two]", got)
+	}
+
+	if _, ok := <-out; ok {
+		t.Fatal("expected out to close once the reader is exhausted")
+	}
+}
+
+func TestReaderStreamerSkipsBlankLines(t *testing.T) {
+	r := strings.NewReader("\n\n" + `{"update_id":1,"message":{"text":"hi"}}` + "\n\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, _ := NewReaderStreamer[Update](r, AsUpdate).Stream(ctx)
+
+	select {
+	case u := <-out:
+		if u.Content.Text != "hi" {
+			t.Fatalf("got %q, want hi", u.Content.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+
+	if _, ok := <-out; ok {
+		t.Fatal("expected out to close once the reader is exhausted")
+	}
+}
+
+func TestReaderStreamerSurfacesDecodeErrors(t *testing.T) {
+	wantErr := errors.New("decode boom")
+	r := strings.NewReader(`{"update_id":1}` + "\n")
+
+	decoder := func(it *jsoniter.Iterator) (Update, error) {
+		it.Skip()
+		return Update{}, wantErr
+	}
+
+	out, errc := NewReaderStreamer[Update](r, decoder).Stream(context.Background())
+
+	select {
+	case <-out:
+		t.Fatal("expected no value on decode error")
+	case err := <-errc:
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("err = %v, want %v", err, wantErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for error")
+	}
+}
@@ -0,0 +1,130 @@
+package telexy
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"time"
+)
+
+// RepeatedMessage reports a run of consecutive identical message texts from
+// the same chat+user, for moderation use (e.g. flagging or muting obvious
+// spam).
+type RepeatedMessage struct {
+	ChatID int64
+	UserID int64
+	Text   string
+	Count  int
+}
+
+// DetectRepeatedMessages relays every update from in to the returned Stream
+// unchanged, and additionally calls onRepeat whenever the same chat+user has
+// sent n or more consecutive identical message texts within window of each
+// other. It's a detector, not a filter: pair it with Filter if spam should
+// also be dropped rather than just flagged. Non-message updates, and
+// updates with an empty Content.Text, always pass through without being
+// tracked.
+//
+// Once a key crosses n, onRepeat fires again on every further repeat of the
+// same text (count keeps climbing), not just once at the threshold, since a
+// caller muting after 3 repeats still wants to know about the 4th and 5th.
+//
+// Per-key history is kept in an LRU bounded by maxKeys, same rationale as
+// ThrottlePerKey: a long-running stream touching many distinct chat+user
+// pairs shouldn't grow this combinator's memory without bound. Inject now
+// for deterministic testing. The returned stream closes once ctx is
+// canceled or in closes.
+func DetectRepeatedMessages(ctx context.Context, in Stream[Update], n int, window time.Duration, maxKeys int, now func() time.Time, onRepeat func(RepeatedMessage)) Stream[Update] {
+	out := make(chan Update)
+	d := &repeatDetector{n: n, window: window, now: now, max: maxKeys, order: list.New(), index: make(map[string]*list.Element)}
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case u, ok := <-in:
+				if !ok {
+					return
+				}
+				if rm, ok := d.observe(u); ok && onRepeat != nil {
+					onRepeat(rm)
+				}
+				select {
+				case out <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// repeatDetector tracks, per chat+user key, the most recent message text and
+// how many times in a row it's been repeated. It's only ever touched from
+// DetectRepeatedMessages' single driving goroutine, so it needs no mutex.
+type repeatDetector struct {
+	n      int
+	window time.Duration
+	now    func() time.Time
+	max    int
+
+	order *list.List
+	index map[string]*list.Element
+}
+
+type repeatDetectorEntry struct {
+	key            string
+	chatID, userID int64
+	text           string
+	count          int
+	last           time.Time
+}
+
+// observe records u against its chat+user key's history, returning the
+// RepeatedMessage to report if this brings that key's run length to at
+// least n.
+func (d *repeatDetector) observe(u Update) (RepeatedMessage, bool) {
+	if u.Content.Text == "" {
+		return RepeatedMessage{}, false
+	}
+	key := fmt.Sprintf("%d:%d", u.Content.ChatID, u.Content.UserID)
+	now := d.now()
+
+	el, ok := d.index[key]
+	if !ok {
+		entry := &repeatDetectorEntry{key: key, chatID: u.Content.ChatID, userID: u.Content.UserID, text: u.Content.Text, count: 1, last: now}
+		el = d.order.PushFront(entry)
+		d.index[key] = el
+		d.evict()
+		return RepeatedMessage{}, false
+	}
+
+	d.order.MoveToFront(el)
+	entry := el.Value.(*repeatDetectorEntry)
+	if entry.text != u.Content.Text || now.Sub(entry.last) > d.window {
+		entry.text = u.Content.Text
+		entry.count = 1
+		entry.last = now
+		return RepeatedMessage{}, false
+	}
+
+	entry.count++
+	entry.last = now
+	if entry.count < d.n {
+		return RepeatedMessage{}, false
+	}
+	return RepeatedMessage{ChatID: entry.chatID, UserID: entry.userID, Text: entry.text, Count: entry.count}, true
+}
+
+// evict drops the least-recently-active key once the LRU exceeds max.
+func (d *repeatDetector) evict() {
+	for d.order.Len() > d.max {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.index, oldest.Value.(*repeatDetectorEntry).key)
+	}
+}
@@ -0,0 +1,100 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/api"
+)
+
+func TestDetectRepeatedMessagesFlagsThirdIdenticalMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var flagged []RepeatedMessage
+	in := make(chan Update)
+	out := DetectRepeatedMessages(ctx, Stream[Update](in), 3, time.Minute, 16, time.Now, func(rm RepeatedMessage) {
+		flagged = append(flagged, rm)
+	})
+
+	send := func(text string) {
+		in <- Update{Content: Content{ChatID: 1, UserID: 2, Text: text}}
+		<-out
+	}
+
+	send("spam")
+	send("spam")
+	if len(flagged) != 0 {
+		t.Fatalf("flagged = %v, want none before reaching n", flagged)
+	}
+	send("spam")
+	if len(flagged) != 1 || flagged[0].Count != 3 || flagged[0].Text != "spam" {
+		t.Fatalf("flagged = %v, want one RepeatedMessage{Text: spam, Count: 3}", flagged)
+	}
+
+	close(in)
+}
+
+func TestDetectRepeatedMessagesDoesNotFlagVariedMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var flagged []RepeatedMessage
+	in := make(chan Update)
+	out := DetectRepeatedMessages(ctx, Stream[Update](in), 3, time.Minute, 16, time.Now, func(rm RepeatedMessage) {
+		flagged = append(flagged, rm)
+	})
+
+	for _, text := range []string{"hello", "how are you", "nice weather"} {
+		in <- Update{Content: Content{ChatID: 1, UserID: 2, Text: text}}
+		<-out
+	}
+	if len(flagged) != 0 {
+		t.Fatalf("flagged = %v, want none for varied messages", flagged)
+	}
+
+	close(in)
+}
+
+func TestDetectRepeatedMessagesTracksChatUserPairsIndependently(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var flagged []RepeatedMessage
+	in := make(chan Update)
+	out := DetectRepeatedMessages(ctx, Stream[Update](in), 2, time.Minute, 16, time.Now, func(rm RepeatedMessage) {
+		flagged = append(flagged, rm)
+	})
+
+	in <- Update{Content: Content{ChatID: 1, UserID: 2, Text: "spam"}}
+	<-out
+	in <- Update{Content: Content{ChatID: 1, UserID: 3, Text: "spam"}}
+	<-out
+	if len(flagged) != 0 {
+		t.Fatalf("flagged = %v, want none: different users shouldn't share a run", flagged)
+	}
+
+	close(in)
+}
+
+func TestDetectRepeatedMessagesIgnoresNonMessageUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var flagged []RepeatedMessage
+	in := make(chan Update)
+	out := DetectRepeatedMessages(ctx, Stream[Update](in), 2, time.Minute, 16, time.Now, func(rm RepeatedMessage) {
+		flagged = append(flagged, rm)
+	})
+
+	for i := 0; i < 3; i++ {
+		in <- Update{UpdateInfo: api.UpdateInfo{Type: api.UpdateTypeMyChatMember}}
+		<-out
+	}
+	if len(flagged) != 0 {
+		t.Fatalf("flagged = %v, want none for updates with empty text", flagged)
+	}
+
+	close(in)
+}
@@ -0,0 +1,56 @@
+package telexy
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// defaultLogBodyLimit bounds how many bytes of a logged request/response
+// body are kept, so a bot sending a large media group's caption (or
+// receiving a big getChatAdministrators response) doesn't flood the log
+// with an entire payload.
+const defaultLogBodyLimit = 2048
+
+// sensitiveBodyFields matches JSON string fields whose value should never
+// be logged verbatim, on top of the token redaction applied separately:
+// a "secret" or bot "token" occasionally shows up in a request body
+// itself (e.g. setWebhook's secret_token), not just the URL.
+var sensitiveBodyFields = regexp.MustCompile(`"(secret_token|secret|token)"\s*:\s*"[^"]*"`)
+
+// redactBody returns data with token (the bot's own, as it would appear in
+// a logged URL) and any sensitive-looking JSON fields replaced, truncated
+// to at most limit bytes. It's meant for debug logging only; it's not a
+// substitute for not logging secrets at all.
+func redactBody(data []byte, token string, limit int) string {
+	s := string(data)
+	if token != "" {
+		s = strings.ReplaceAll(s, token, "[REDACTED]")
+	}
+	s = sensitiveBodyFields.ReplaceAllString(s, `"$1":"[REDACTED]"`)
+
+	if limit > 0 && len(s) > limit {
+		s = s[:limit] + "...(truncated)"
+	}
+	return s
+}
+
+// logRequest and logResponse emit opt-in debug logging of a Do attempt's
+// raw bodies, redacted via redactBody. They're no-ops when c.logBodies is
+// false, and use tlxlog.Debug so a Logger that doesn't implement
+// DebugLogger simply never sees them, keeping the feature performance
+// neutral when off or unsupported.
+func (c *Client) logRequest(method string, body []byte) {
+	if !c.logBodies {
+		return
+	}
+	tlxlog.Debug(c.logger, "request body", "method", method, "body", redactBody(body, c.token(), c.logBodyLimit))
+}
+
+func (c *Client) logResponse(method string, body []byte) {
+	if !c.logBodies {
+		return
+	}
+	tlxlog.Debug(c.logger, "response body", "method", method, "body", redactBody(body, c.token(), c.logBodyLimit))
+}
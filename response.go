@@ -0,0 +1,79 @@
+package telexy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// readResponse decodes the Telegram Bot API envelope {"ok", "result",
+// "error_code", "description"} from resp, invoking consume on the iterator
+// positioned at the "result" field when the call succeeded.
+func readResponse(method string, resp *http.Response, consume func(*jsoniter.Iterator) error) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("telexy: read response: %w", err)
+	}
+
+	it := json.BorrowIterator(data)
+	defer json.ReturnIterator(it)
+
+	var (
+		ok              bool
+		errorCode       int
+		description     string
+		migrateToChatID int64
+		retryAfter      time.Duration
+		consumeErr      error
+	)
+	it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "ok":
+			ok = it.ReadBool()
+		case "error_code":
+			errorCode = it.ReadInt()
+		case "description":
+			description = it.ReadString()
+		case "parameters":
+			it.ReadObjectCB(func(it *jsoniter.Iterator, field string) bool {
+				switch field {
+				case "migrate_to_chat_id":
+					migrateToChatID = it.ReadInt64()
+				case "retry_after":
+					retryAfter = time.Duration(it.ReadInt64()) * time.Second
+				default:
+					it.Skip()
+				}
+				return true
+			})
+		case "result":
+			if consume != nil {
+				consumeErr = consume(it)
+			} else {
+				it.Skip()
+			}
+		default:
+			it.Skip()
+		}
+		return consumeErr == nil
+	})
+	if consumeErr != nil {
+		return consumeErr
+	}
+	if it.Error != nil && it.Error != io.EOF {
+		return fmt.Errorf("telexy: decode response: %w", it.Error)
+	}
+	if !ok {
+		return &APIError{
+			Method:          method,
+			Code:            errorCode,
+			Description:     description,
+			MigrateToChatID: migrateToChatID,
+			RetryAfter:      retryAfter,
+		}
+	}
+	return nil
+}
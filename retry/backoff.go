@@ -0,0 +1,83 @@
+// Package retry provides small, dependency-free helpers for retrying
+// fallible operations with exponential backoff.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Backoff computes exponentially increasing delays between retry attempts,
+// bounded by Min and Max. The zero value is not usable; Min, Max and Factor
+// must be set.
+//
+// A Backoff is safe for concurrent use.
+type Backoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+
+	// Jitter, if true, randomizes each delay Next returns to somewhere
+	// between 0 and the otherwise-computed delay (full jitter). Without
+	// it, many clients that started retrying at the same moment (e.g.
+	// every bot instance reconnecting after a shared Telegram outage)
+	// stay in lockstep forever, hammering the API with synchronized
+	// bursts instead of spreading load out.
+	Jitter bool
+
+	attempt uint64
+	rand    func() float64                                   // overridable in tests; nil means rand.Float64
+	sleep   func(ctx context.Context, d time.Duration) error // overridable in tests; nil means a real timer
+}
+
+// Next returns the delay to wait before the next attempt and advances the
+// internal attempt counter.
+func (b *Backoff) Next() time.Duration {
+	attempt := atomic.AddUint64(&b.attempt, 1) - 1
+	delay := float64(b.Min) * math.Pow(b.Factor, float64(attempt))
+	if delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if delay < float64(b.Min) {
+		delay = float64(b.Min)
+	}
+	if b.Jitter {
+		delay *= b.randFloat64()
+	}
+	return time.Duration(delay)
+}
+
+func (b *Backoff) randFloat64() float64 {
+	if b.rand != nil {
+		return b.rand()
+	}
+	return rand.Float64()
+}
+
+// Reset returns the backoff to its initial state, so the next call to Next
+// returns a delay of Min.
+func (b *Backoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// wait pauses for d, or until ctx is canceled, returning ctx.Err() in the
+// latter case. Tests set b.sleep to record delays instead of actually
+// waiting them out, letting them assert on the schedule Next() produces
+// without slowing the suite down.
+func (b *Backoff) wait(ctx context.Context, d time.Duration) error {
+	if b.sleep != nil {
+		return b.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffExponentialGrowthBoundedByMax(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Millisecond, Max: 100 * time.Millisecond, Factor: 2}
+
+	want := []time.Duration{10, 20, 40, 80, 100, 100}
+	for i, w := range want {
+		if got := b.Next(); got != w*time.Millisecond {
+			t.Fatalf("attempt %d: Next() = %v, want %v", i, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestBackoffResetReturnsToMin(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Millisecond, Max: 100 * time.Millisecond, Factor: 2}
+	b.Next()
+	b.Next()
+	b.Reset()
+
+	if got := b.Next(); got != 10*time.Millisecond {
+		t.Fatalf("Next() after Reset = %v, want 10ms", got)
+	}
+}
+
+func TestBackoffJitterStaysWithinComputedDelay(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Millisecond, Max: 100 * time.Millisecond, Factor: 2, Jitter: true}
+	b.rand = func() float64 { return 0.5 }
+
+	// Attempt 0's unjittered delay is Min (10ms); with rand fixed at 0.5,
+	// jitter should scale it down to exactly half.
+	if got := b.Next(); got != 5*time.Millisecond {
+		t.Fatalf("Next() = %v, want 5ms (half of the 10ms unjittered delay)", got)
+	}
+}
+
+func TestBackoffJitterVariesAcrossCalls(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Second, Factor: 2, Jitter: true}
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		seen[b.Next()] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected jittered delays to vary across calls, got %v distinct values", len(seen))
+	}
+}
@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget is a token-bucket limiter on the total number of retries that may
+// be performed, used to stop many concurrent callers' independent retries
+// from compounding into a retry storm during a widespread outage.
+//
+// A Budget is safe for concurrent use. The zero value is not usable; use
+// NewBudget.
+type Budget struct {
+	mu       sync.Mutex
+	tokens   float64
+	max      float64
+	refill   float64 // tokens per second
+	lastFill time.Time
+
+	now func() time.Time // overridable in tests
+}
+
+// NewBudget creates a Budget that holds up to max retries at once, refilled
+// at refill tokens per second.
+func NewBudget(max, refill float64) *Budget {
+	return &Budget{
+		tokens:   max,
+		max:      max,
+		refill:   refill,
+		lastFill: time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Take withdraws one token from the budget, reporting whether a retry may
+// proceed. Once exhausted, callers should fail fast rather than retry.
+func (b *Budget) Take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	elapsed := now.Sub(b.lastFill).Seconds()
+	b.lastFill = now
+
+	b.tokens += elapsed * b.refill
+	if b.tokens > b.max {
+		b.tokens = b.max
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,27 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetExhaustionAndRefill(t *testing.T) {
+	b := NewBudget(2, 1)
+	fakeNow := b.lastFill
+	b.now = func() time.Time { return fakeNow }
+
+	if !b.Take() {
+		t.Fatal("expected first token to be available")
+	}
+	if !b.Take() {
+		t.Fatal("expected second token to be available")
+	}
+	if b.Take() {
+		t.Fatal("expected budget to be exhausted")
+	}
+
+	fakeNow = fakeNow.Add(2 * time.Second)
+	if !b.Take() {
+		t.Fatal("expected budget to refill after elapsed time")
+	}
+}
@@ -0,0 +1,32 @@
+package retry
+
+import "errors"
+
+// fatalError marks an error as one that Recover and RecoverN should return
+// immediately instead of retrying, even though ctx is still live and
+// maxAttempts hasn't been reached.
+type fatalError struct {
+	err error
+}
+
+func (f *fatalError) Error() string { return f.err.Error() }
+func (f *fatalError) Unwrap() error { return f.err }
+
+// Fatal wraps err so that Recover and RecoverN stop retrying and return it
+// right away. Use it for failures no amount of waiting will fix, such as a
+// long poll's token having been revoked: without this, RecoverN would keep
+// spending attempts (or, with no limit, spin forever) on a call that can
+// never succeed.
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fatalError{err: err}
+}
+
+// IsFatal reports whether err, or an error it wraps, was marked with
+// Fatal.
+func IsFatal(err error) bool {
+	var f *fatalError
+	return errors.As(err, &f)
+}
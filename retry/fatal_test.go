@@ -0,0 +1,31 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsFatalDetectsWrappedFatalError(t *testing.T) {
+	base := errors.New("permission denied")
+	wrapped := fmt.Errorf("request failed: %w", Fatal(base))
+
+	if !IsFatal(wrapped) {
+		t.Fatalf("IsFatal(wrapped) = false, want true")
+	}
+	if !errors.Is(wrapped, base) {
+		t.Fatalf("expected wrapped to unwrap to base")
+	}
+}
+
+func TestIsFatalRejectsPlainError(t *testing.T) {
+	if IsFatal(errors.New("plain")) {
+		t.Fatalf("IsFatal(plain error) = true, want false")
+	}
+}
+
+func TestFatalNilReturnsNil(t *testing.T) {
+	if Fatal(nil) != nil {
+		t.Fatalf("Fatal(nil) = %v, want nil", Fatal(nil))
+	}
+}
@@ -0,0 +1,42 @@
+package retry
+
+import (
+	"context"
+)
+
+// Recover repeatedly invokes fn, waiting according to b between attempts,
+// until fn returns a nil error or ctx is canceled. The context error is
+// returned in the latter case.
+func Recover(ctx context.Context, b *Backoff, fn func(ctx context.Context) error) error {
+	return RecoverN(ctx, b, fn, 0)
+}
+
+// RecoverN behaves like Recover, but gives up and returns the last error
+// from fn once it has failed maxAttempts times, instead of retrying
+// forever. A permanently broken operation (e.g. a long poll whose token
+// was revoked, returning 401 on every call) would otherwise spin until ctx
+// is canceled, which may be never for a long-running process. maxAttempts
+// <= 0 means no limit, matching Recover.
+func RecoverN(ctx context.Context, b *Backoff, fn func(ctx context.Context) error, maxAttempts int) error {
+	var attempts int
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if IsFatal(err) {
+			return err
+		}
+		attempts++
+		if maxAttempts > 0 && attempts >= maxAttempts {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := b.wait(ctx, b.Next()); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecoverNGivesUpAfterMaxAttempts(t *testing.T) {
+	b := &Backoff{Min: time.Millisecond, Max: time.Millisecond, Factor: 2}
+	wantErr := errors.New("permanently broken")
+
+	var calls int
+	err := RecoverN(context.Background(), b, func(context.Context) error {
+		calls++
+		return wantErr
+	}, 3)
+
+	if err != wantErr {
+		t.Fatalf("err = %v, want %v", err, wantErr)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRecoverNSucceedsBeforeExhaustingAttempts(t *testing.T) {
+	b := &Backoff{Min: time.Millisecond, Max: time.Millisecond, Factor: 2}
+
+	var calls int
+	err := RecoverN(context.Background(), b, func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("transient")
+		}
+		return nil
+	}, 5)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+}
+
+// TestRecoverReturnsPromptlyOnCancellation guards Recover's existing
+// cancellation handling: it sleeps between attempts via a time.Timer
+// selected against ctx.Done(), so a canceled caller doesn't have to wait
+// out the full backoff delay before Recover notices and returns.
+func TestRecoverReturnsPromptlyOnCancellation(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Second, Max: 10 * time.Second, Factor: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := Recover(ctx, b, func(context.Context) error { return errors.New("always fails") })
+	elapsed := time.Since(start)
+
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("Recover took %v to notice cancellation, want well under the 10s backoff", elapsed)
+	}
+}
+
+func TestRecoverNReturnsFatalErrorImmediately(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Second, Max: 10 * time.Second, Factor: 1}
+	wantErr := Fatal(errors.New("token revoked"))
+
+	var calls int
+	start := time.Now()
+	err := RecoverN(context.Background(), b, func(context.Context) error {
+		calls++
+		return wantErr
+	}, 0)
+	elapsed := time.Since(start)
+
+	if err != wantErr {
+		t.Fatalf("err = %v, want %v", err, wantErr)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("RecoverN took %v to return a fatal error, want immediate", elapsed)
+	}
+	if !IsFatal(err) {
+		t.Fatalf("IsFatal(err) = false, want true")
+	}
+}
+
+func TestRecoverNRecordsDelaySequenceViaInjectedSleep(t *testing.T) {
+	b := &Backoff{Min: 10 * time.Millisecond, Max: 100 * time.Millisecond, Factor: 2}
+
+	var delays []time.Duration
+	b.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	var calls int
+	err := RecoverN(context.Background(), b, func(context.Context) error {
+		calls++
+		if calls < 5 {
+			return errors.New("transient")
+		}
+		return nil
+	}, 0)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []time.Duration{10, 20, 40, 80}
+	if len(delays) != len(want) {
+		t.Fatalf("recorded %d delays, want %d: %v", len(delays), len(want), delays)
+	}
+	for i, w := range want {
+		if delays[i] != w*time.Millisecond {
+			t.Fatalf("delay %d = %v, want %v", i, delays[i], w*time.Millisecond)
+		}
+	}
+}
+
+func TestRecoverMatchesRecoverNWithNoLimit(t *testing.T) {
+	b := &Backoff{Min: time.Millisecond, Max: time.Millisecond, Factor: 2}
+
+	var calls int
+	err := Recover(context.Background(), b, func(context.Context) error {
+		calls++
+		if calls < 5 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 5 {
+		t.Fatalf("calls = %d, want 5", calls)
+	}
+}
@@ -0,0 +1,65 @@
+package telexy
+
+import "context"
+
+// Scan relays an accumulator derived from in to the returned Stream: each
+// value v received from in updates the accumulator via acc = f(acc, v),
+// starting from init, and the updated acc is emitted. It's the basis for
+// running aggregates such as per-minute message counts or rolling stats.
+// The returned stream closes once ctx is canceled or in closes.
+func Scan[T, A any](ctx context.Context, in Stream[T], init A, f func(A, T) A) Stream[A] {
+	out := make(chan A)
+
+	go func() {
+		defer close(out)
+		acc := init
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				acc = f(acc, v)
+				select {
+				case out <- acc:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Reduce folds every value from in into a single accumulator via
+// acc = f(acc, v), starting from init, and emits only the final result once
+// in closes. Unlike Scan, the returned stream never closes until in does (or
+// ctx is canceled), since there's nothing to emit before then.
+func Reduce[T, A any](ctx context.Context, in Stream[T], init A, f func(A, T) A) Stream[A] {
+	out := make(chan A, 1)
+
+	go func() {
+		defer close(out)
+		acc := init
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					select {
+					case out <- acc:
+					case <-ctx.Done():
+					}
+					return
+				}
+				acc = f(acc, v)
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,91 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScanEmitsRunningAccumulator(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	out := Scan(ctx, in, 0, func(acc, v int) int { return acc + v })
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	want := []int{1, 3, 6}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestScanHandlesEmptyInput(t *testing.T) {
+	in := make(chan int)
+	close(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	out := Scan(ctx, in, 0, func(acc, v int) int { return acc + v })
+
+	if _, ok := <-out; ok {
+		t.Fatal("expected no values from Scan over an empty input")
+	}
+}
+
+func TestReduceEmitsOnlyFinalAccumulator(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	out := Reduce(ctx, in, 0, func(acc, v int) int { return acc + v })
+
+	got, ok := <-out
+	if !ok {
+		t.Fatal("expected a final value from Reduce")
+	}
+	if got != 6 {
+		t.Fatalf("got %d, want 6", got)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("expected Reduce's stream to close after the final value")
+	}
+}
+
+func TestReduceHandlesEmptyInput(t *testing.T) {
+	in := make(chan int)
+	close(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	out := Reduce(ctx, in, 42, func(acc, v int) int { return acc + v })
+
+	got, ok := <-out
+	if !ok {
+		t.Fatal("expected the init value from Reduce over an empty input")
+	}
+	if got != 42 {
+		t.Fatalf("got %d, want 42 (the init value)", got)
+	}
+}
@@ -0,0 +1,95 @@
+package telexy
+
+import (
+	"context"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// SendOptions holds the parameters common to most of Telegram's send*
+// methods, meant to be embedded into request types such as
+// SendMessageRequest.
+type SendOptions struct {
+	// DisableNotification sends the message silently; users receive a
+	// notification with no sound.
+	DisableNotification bool `json:"disable_notification,omitempty"`
+
+	// ProtectContent prevents the message from being forwarded or saved.
+	ProtectContent bool `json:"protect_content,omitempty"`
+
+	// MessageEffectID requests one of Telegram's animated message
+	// effects (e.g. a burst of confetti). Only honored in private chats.
+	MessageEffectID string `json:"message_effect_id,omitempty"`
+
+	// BusinessConnectionID sends on behalf of a connected Telegram
+	// Business account rather than the bot itself, identified by the
+	// connection ID Telegram reports in a business_connection update.
+	BusinessConnectionID string `json:"business_connection_id,omitempty"`
+}
+
+// LinkPreviewOptions controls the link preview Telegram generates for a
+// message's first URL, replacing the deprecated disable_web_page_preview
+// boolean with finer-grained control.
+type LinkPreviewOptions struct {
+	// IsDisabled suppresses the link preview entirely.
+	IsDisabled bool `json:"is_disabled,omitempty"`
+
+	// URL overrides which URL the preview is generated for, instead of
+	// the first one found in the message text.
+	URL string `json:"url,omitempty"`
+
+	// PreferSmallMedia/PreferLargeMedia request a smaller or larger
+	// preview media size than Telegram's default choice.
+	PreferSmallMedia bool `json:"prefer_small_media,omitempty"`
+	PreferLargeMedia bool `json:"prefer_large_media,omitempty"`
+
+	// ShowAboveText renders the preview above the message text instead
+	// of below it.
+	ShowAboveText bool `json:"show_above_text,omitempty"`
+}
+
+// SendMessageRequest is the request body for sendMessage.
+type SendMessageRequest struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+
+	// DisableWebPagePreview is Telegram's original, now-deprecated way of
+	// suppressing the link preview. Kept for bots that haven't migrated
+	// yet; prefer LinkPreviewOptions.IsDisabled in new code. Setting both
+	// this and LinkPreviewOptions is rejected by Validate, since it's
+	// ambiguous which one Telegram would honor.
+	DisableWebPagePreview bool `json:"disable_web_page_preview,omitempty"`
+
+	// LinkPreviewOptions, if set, controls the message's link preview.
+	// Supersedes DisableWebPagePreview.
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
+
+	SendOptions
+}
+
+// Validate reports an error if r's fields are inconsistent in a way
+// Telegram's API wouldn't reject outright but would resolve ambiguously:
+// currently, setting both the legacy DisableWebPagePreview and the newer
+// LinkPreviewOptions.
+func (r *SendMessageRequest) Validate() error {
+	if r.DisableWebPagePreview && r.LinkPreviewOptions != nil {
+		return fmt.Errorf("telexy: SendMessageRequest: DisableWebPagePreview and LinkPreviewOptions are mutually exclusive")
+	}
+	return nil
+}
+
+// SendMessage sends a text message per req, returning the message Telegram
+// created.
+func (c *Client) SendMessage(ctx context.Context, req SendMessageRequest) (Message, error) {
+	if err := req.Validate(); err != nil {
+		return Message{}, err
+	}
+
+	var msg Message
+	err := c.Do(ctx, "sendMessage", &req, func(it *jsoniter.Iterator) error {
+		it.ReadVal(&msg)
+		return it.Error
+	})
+	return msg, err
+}
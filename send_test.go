@@ -0,0 +1,160 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendMessageRequestMarshalsLinkPreviewOptions(t *testing.T) {
+	req := SendMessageRequest{
+		ChatID: 42,
+		Text:   "hi",
+		LinkPreviewOptions: &LinkPreviewOptions{
+			IsDisabled:    true,
+			ShowAboveText: true,
+		},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{`"link_preview_options":{`, `"is_disabled":true`, `"show_above_text":true`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("marshaled %s, missing %s", got, want)
+		}
+	}
+	if strings.Contains(got, "disable_web_page_preview") {
+		t.Fatalf("marshaled %s, expected omitempty to drop the unset legacy field", got)
+	}
+}
+
+func TestSendMessageRequestValidateRejectsBothPreviewOptions(t *testing.T) {
+	req := &SendMessageRequest{
+		ChatID:                42,
+		Text:                  "hi",
+		DisableWebPagePreview: true,
+		LinkPreviewOptions:    &LinkPreviewOptions{IsDisabled: true},
+	}
+	if err := req.Validate(); err == nil {
+		t.Fatal("expected Validate to reject setting both preview options")
+	}
+}
+
+func TestSendMessageRequestValidateAllowsEitherAlone(t *testing.T) {
+	legacy := &SendMessageRequest{ChatID: 42, Text: "hi", DisableWebPagePreview: true}
+	if err := legacy.Validate(); err != nil {
+		t.Fatalf("unexpected error for legacy-only request: %v", err)
+	}
+
+	modern := &SendMessageRequest{ChatID: 42, Text: "hi", LinkPreviewOptions: &LinkPreviewOptions{IsDisabled: true}}
+	if err := modern.Validate(); err != nil {
+		t.Fatalf("unexpected error for LinkPreviewOptions-only request: %v", err)
+	}
+}
+
+func TestSendMessageRequestMarshalsSendOptions(t *testing.T) {
+	req := SendMessageRequest{
+		ChatID: 42,
+		Text:   "hi",
+		SendOptions: SendOptions{
+			DisableNotification: true,
+			ProtectContent:      true,
+		},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{`"disable_notification":true`, `"protect_content":true`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("marshaled %s, missing %s", got, want)
+		}
+	}
+}
+
+func TestSendMessageRequestMarshalsEffectAndBusinessConnection(t *testing.T) {
+	req := SendMessageRequest{
+		ChatID: 42,
+		Text:   "hi",
+		SendOptions: SendOptions{
+			MessageEffectID:      "effect1",
+			BusinessConnectionID: "conn1",
+		},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{`"message_effect_id":"effect1"`, `"business_connection_id":"conn1"`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("marshaled %s, missing %s", got, want)
+		}
+	}
+}
+
+func TestSendMessageDecodesMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"message_id":7,"date":1700000000}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := c.SendMessage(context.Background(), SendMessageRequest{ChatID: 1, Text: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != 7 || msg.Date != 1700000000 {
+		t.Fatalf("got %+v, want {ID:7 Date:1700000000}", msg)
+	}
+}
+
+func TestSendMessageRejectsInvalidRequestWithoutCallingAPI(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := SendMessageRequest{
+		ChatID:                1,
+		Text:                  "hi",
+		DisableWebPagePreview: true,
+		LinkPreviewOptions:    &LinkPreviewOptions{IsDisabled: true},
+	}
+	if _, err := c.SendMessage(context.Background(), req); err == nil {
+		t.Fatal("expected an error for an invalid request")
+	}
+	if calls != 0 {
+		t.Fatalf("expected no HTTP call for an invalid request, got %d", calls)
+	}
+}
+
+func TestSendMessageRequestOmitsEffectAndBusinessConnectionWhenUnset(t *testing.T) {
+	req := SendMessageRequest{ChatID: 42, Text: "hi"}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, unwanted := range []string{"message_effect_id", "business_connection_id"} {
+		if strings.Contains(got, unwanted) {
+			t.Fatalf("marshaled %s, expected omitempty to drop unset %s", got, unwanted)
+		}
+	}
+}
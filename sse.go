@@ -0,0 +1,45 @@
+package telexy
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ServeSSE serves s as a Server-Sent Events stream on w, re-serializing
+// each update to JSON and writing it as a "data:" event, flushing after
+// every event so browser clients (dashboards, live activity feeds) see
+// updates as they arrive. It returns once r's context is canceled (the
+// client disconnected) or s closes, without leaking a goroutine.
+func ServeSSE(w http.ResponseWriter, r *http.Request, s Stream[Update]) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "telexy: streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-s:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(u)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/api"
+)
+
+func TestServeSSEWritesUpdatesAndStopsWhenStreamCloses(t *testing.T) {
+	s := make(chan Update, 1)
+	s <- Update{UpdateInfo: api.UpdateInfo{ID: 1, Type: "message"}, Content: Content{Text: "hi"}}
+	close(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+	rec := httptest.NewRecorder()
+
+	ServeSSE(rec, req, s)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "data: ") {
+		t.Fatalf("body doesn't start with an SSE data event: %s", body)
+	}
+	if !strings.Contains(body, `"Text":"hi"`) {
+		t.Fatalf("body missing update payload: %s", body)
+	}
+}
+
+func TestServeSSEStopsWhenRequestContextCanceled(t *testing.T) {
+	s := make(chan Update)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/sse", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		ServeSSE(rec, req, s)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ServeSSE didn't return after request context was canceled")
+	}
+}
@@ -0,0 +1,22 @@
+package telexy
+
+import "context"
+
+// Stream is a receive-only channel of values of type T, the shape accepted
+// and produced by stream combinators such as DropStale.
+type Stream[T any] = <-chan T
+
+// Streamer produces a stream of values of type T alongside a parallel error
+// stream. Implementations should close both returned channels once ctx is
+// canceled and no more values will be produced.
+type Streamer[T any] interface {
+	Stream(ctx context.Context) (<-chan T, <-chan error)
+}
+
+// StreamerFunc adapts a plain function into a Streamer.
+type StreamerFunc[T any] func(ctx context.Context) (<-chan T, <-chan error)
+
+// Stream calls f.
+func (f StreamerFunc[T]) Stream(ctx context.Context) (<-chan T, <-chan error) {
+	return f(ctx)
+}
@@ -0,0 +1,33 @@
+package telexy
+
+import "context"
+
+// OnUpdateSync drains s in a single goroutine, invoking handler once per
+// update in the exact order they were received, waiting for each call to
+// return before pulling the next one. This is the "just give me updates in
+// order" escape hatch for bots simple enough that Mux's concurrent worker
+// fan-out — and the cross-handler reordering it can introduce — is
+// unnecessary complexity: with OnUpdateSync, ordering is correct by
+// construction, at the cost of handler's own latency gating how fast
+// updates are drained. It doesn't scale to high traffic or a slow handler;
+// use Mux for that.
+//
+// telexy has no Bot type to hang this off of as a method (bots are built
+// by wiring a Streamer into Mux.Dispatch or a handler directly, not through
+// a central object), so this is a plain function taking the Streamer to
+// drain instead.
+//
+// OnUpdateSync starts s and returns immediately. The returned channel
+// relays s's own error stream as-is; like any Streamer's error channel, it
+// closes once s's Stream goroutine does (normally once ctx is canceled).
+func OnUpdateSync(ctx context.Context, s Streamer[Update], handler func(Update)) <-chan error {
+	out, errc := s.Stream(ctx)
+
+	go func() {
+		for u := range out {
+			handler(u)
+		}
+	}()
+
+	return errc
+}
@@ -0,0 +1,99 @@
+package telexy
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy/api"
+)
+
+func TestOnUpdateSyncProcessesUpdatesInReceiptOrder(t *testing.T) {
+	in := make(chan Update, 3)
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 1}}
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 2}}
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 3}}
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var got []int64
+	done := make(chan struct{})
+
+	errc := OnUpdateSync(ctx, ChannelStreamer[Update](in), func(u Update) {
+		mu.Lock()
+		got = append(got, u.ID)
+		if len(got) == 3 {
+			close(done)
+		}
+		mu.Unlock()
+	})
+	_ = errc
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for all updates to be processed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []int64{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, id := range want {
+		if got[i] != id {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestOnUpdateSyncWaitsForHandlerBeforeNextUpdate(t *testing.T) {
+	in := make(chan Update, 2)
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 1}}
+	in <- Update{UpdateInfo: api.UpdateInfo{ID: 2}}
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var maxConcurrent, current int
+
+	done := make(chan struct{})
+	var count int
+	OnUpdateSync(ctx, ChannelStreamer[Update](in), func(u Update) {
+		mu.Lock()
+		current++
+		if current > maxConcurrent {
+			maxConcurrent = current
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		current--
+		count++
+		if count == 2 {
+			close(done)
+		}
+		mu.Unlock()
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for both updates to be processed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxConcurrent != 1 {
+		t.Fatalf("maxConcurrent = %d, want 1 (handlers must run sequentially)", maxConcurrent)
+	}
+}
@@ -0,0 +1,57 @@
+package telexy
+
+import "context"
+
+// Tee relays every value from in to n independent output streams, so
+// multiple consumers can each see the same source without polling (or
+// decoding) it more than once — e.g. routing on AsUpdate's Update while
+// also handing a fully typed decode of the same raw update to another
+// consumer. bufferSize sizes each output channel, so one slow consumer
+// backs up its own buffer rather than stalling delivery to the others
+// immediately; once a slow consumer's buffer fills too, though, Tee blocks
+// on it like any other channel send, which in turn stalls reading from in,
+// so a consistently slow consumer still eventually backs up the whole tee.
+// All outputs close once in closes or ctx is canceled. n <= 0 returns nil.
+func Tee[T any](ctx context.Context, in Stream[T], n int, bufferSize int) []Stream[T] {
+	if n <= 0 {
+		return nil
+	}
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+
+	outs := make([]chan T, n)
+	result := make([]Stream[T], n)
+	for i := range outs {
+		outs[i] = make(chan T, bufferSize)
+		result[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				for _, out := range outs {
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return result
+}
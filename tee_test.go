@@ -0,0 +1,89 @@
+package telexy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTeeDeliversEveryValueToEveryOutput(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	outs := Tee(ctx, in, 3, 1)
+	if len(outs) != 3 {
+		t.Fatalf("got %d outputs, want 3", len(outs))
+	}
+
+	for i, out := range outs {
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+			t.Fatalf("output %d got %v, want [1 2 3]", i, got)
+		}
+	}
+}
+
+func TestTeeClosesAllOutputsWhenSourceCloses(t *testing.T) {
+	in := make(chan int)
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	outs := Tee(ctx, in, 2, 0)
+
+	deadline := time.After(time.Second)
+	for _, out := range outs {
+		select {
+		case _, ok := <-out:
+			if ok {
+				t.Fatal("expected output to be immediately closed")
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for output to close")
+		}
+	}
+}
+
+func TestTeeSlowConsumerDoesNotBlockOthersUntilItsBufferFills(t *testing.T) {
+	in := make(chan int, 1)
+	in <- 1
+	defer close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	outs := Tee(ctx, in, 2, 2)
+
+	// out[1] is never drained; with a buffer of 2, Tee should still be able
+	// to deliver the single pending value to out[0] without waiting on it.
+	select {
+	case v := <-outs[0]:
+		if v != 1 {
+			t.Fatalf("got %d, want 1", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the fast consumer's value")
+	}
+}
+
+func TestTeeReturnsNilForNonPositiveN(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan int)
+	defer close(in)
+
+	if outs := Tee(ctx, in, 0, 1); outs != nil {
+		t.Fatalf("got %v, want nil", outs)
+	}
+}
@@ -0,0 +1,212 @@
+// Package telexytest provides a deterministic fake implementing Telegram's
+// getUpdates long-poll endpoint, for testing bots end-to-end against
+// realistic server behavior (queued updates, long-poll holds, rate limits
+// and gateway errors, and offset-based acking) without talking to the real
+// Bot API.
+package telexytest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RawUpdate is a single getUpdates result entry: the update's ID, used by
+// FakeServer to filter by offset and track acks, and its JSON payload,
+// which must itself carry a matching "update_id" field to look authentic
+// to a decoding client.
+type RawUpdate struct {
+	ID      int64
+	Payload json.RawMessage
+}
+
+type injectedError struct {
+	status      int
+	errorCode   int
+	description string
+}
+
+// FakeServer simulates Telegram's getUpdates endpoint as an http.Handler,
+// meant to be wrapped in an httptest.Server and pointed to with a Client's
+// endpoint option. Like the real endpoint, updates stay pending until a
+// request's offset passes them, at which point they're considered acked
+// and dropped from the log; AckedIDs exposes that history. The zero value
+// is ready to use. A FakeServer is safe for concurrent use.
+type FakeServer struct {
+	mu      sync.Mutex
+	updates []RawUpdate
+	acked   []int64
+	holdFor time.Duration
+	inject  []injectedError
+}
+
+// NewFakeServer creates an empty FakeServer; Enqueue, InjectError and
+// SetHoldDuration configure its behavior before (or while) it's served.
+func NewFakeServer() *FakeServer {
+	return &FakeServer{}
+}
+
+// Enqueue appends updates to the pending log, to be returned by getUpdates
+// calls whose offset is at or below each update's ID. Updates should be
+// enqueued in ascending ID order, matching how Telegram itself delivers
+// them.
+func (s *FakeServer) Enqueue(updates ...RawUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, updates...)
+}
+
+// SetHoldDuration controls how long getUpdates blocks before responding
+// with an empty result when nothing is pending, simulating Telegram's
+// long-poll hold. It defaults to 0, responding immediately.
+func (s *FakeServer) SetHoldDuration(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holdFor = d
+}
+
+// InjectError queues a single error response — e.g. 429 Too Many Requests
+// or a 502/504 gateway blip — to be returned instead of the next
+// getUpdates reply. Errors are served in the order injected, ahead of any
+// offset handling, then serving falls back to normal behavior.
+func (s *FakeServer) InjectError(status, errorCode int, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inject = append(s.inject, injectedError{status: status, errorCode: errorCode, description: description})
+}
+
+// AckedIDs returns the IDs of every update acked so far — an update is
+// acked once a later getUpdates call's offset passes it — in the order
+// they were acked. Tests can use this to verify offset persistence,
+// dedup, or drain-on-stop logic actually progressed the real protocol.
+func (s *FakeServer) AckedIDs() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]int64, len(s.acked))
+	copy(out, s.acked)
+	return out
+}
+
+// ServeHTTP implements http.Handler, serving every request as a
+// getUpdates call.
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err, ok := s.nextInjectedError(); ok {
+		writeError(w, err)
+		return
+	}
+
+	offset, err := readOffset(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pending, ok := s.pollAt(offset)
+	if !ok {
+		hold := s.getHoldDuration()
+		if hold > 0 {
+			select {
+			case <-time.After(hold):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		pending, _ = s.pollAt(offset)
+	}
+
+	writeResult(w, pending)
+}
+
+func readOffset(r *http.Request) (int64, error) {
+	if r.Body == nil {
+		return 0, nil
+	}
+	defer r.Body.Close()
+
+	var body struct {
+		Offset int64 `json:"offset"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		if err == io.EOF {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return body.Offset, nil
+}
+
+func (s *FakeServer) nextInjectedError() (injectedError, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.inject) == 0 {
+		return injectedError{}, false
+	}
+	err := s.inject[0]
+	s.inject = s.inject[1:]
+	return err, true
+}
+
+// pollAt acks every update below offset, then returns the updates at or
+// above it, reporting whether any were pending.
+func (s *FakeServer) pollAt(offset int64) ([]RawUpdate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offset > 0 {
+		s.ackBelowLocked(offset)
+	}
+
+	var pending []RawUpdate
+	for _, u := range s.updates {
+		if u.ID >= offset {
+			pending = append(pending, u)
+		}
+	}
+	return pending, len(pending) > 0
+}
+
+// ackBelowLocked removes every update with ID < offset from the pending
+// log and records it as acked, mirroring how a client's advancing offset
+// tells Telegram it may forget those updates. s.mu must be held.
+func (s *FakeServer) ackBelowLocked(offset int64) {
+	var remaining []RawUpdate
+	for _, u := range s.updates {
+		if u.ID < offset {
+			s.acked = append(s.acked, u.ID)
+		} else {
+			remaining = append(remaining, u)
+		}
+	}
+	s.updates = remaining
+}
+
+func (s *FakeServer) getHoldDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holdFor
+}
+
+func writeError(w http.ResponseWriter, err injectedError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"ok":          false,
+		"error_code":  err.errorCode,
+		"description": err.description,
+	})
+}
+
+func writeResult(w http.ResponseWriter, updates []RawUpdate) {
+	payloads := make([]json.RawMessage, len(updates))
+	for i, u := range updates {
+		payloads[i] = u.Payload
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"ok":     true,
+		"result": payloads,
+	})
+}
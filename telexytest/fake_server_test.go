@@ -0,0 +1,111 @@
+package telexytest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/renbou/telexy"
+)
+
+func newTestClient(t *testing.T, srv *httptest.Server) *telexy.Client {
+	t.Helper()
+	c, err := telexy.NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestFakeServerServesQueuedUpdates(t *testing.T) {
+	fake := NewFakeServer()
+	fake.Enqueue(
+		RawUpdate{ID: 1, Payload: json.RawMessage(`{"update_id":1,"message":{"text":"hi"}}`)},
+		RawUpdate{ID: 2, Payload: json.RawMessage(`{"update_id":2,"message":{"text":"bye"}}`)},
+	)
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	lp := telexy.NewLongPoll[telexy.Update](c, telexy.LongPollOptions[telexy.Update]{Decoder: telexy.AsUpdate})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		select {
+		case u := <-out:
+			got = append(got, u.Content.Text)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for updates")
+		}
+	}
+
+	if got[0] != "hi" || got[1] != "bye" {
+		t.Fatalf("got %v, want [hi bye]", got)
+	}
+}
+
+func TestFakeServerInjectsErrorThenRecovers(t *testing.T) {
+	fake := NewFakeServer()
+	fake.InjectError(http.StatusTooManyRequests, http.StatusTooManyRequests, "Too Many Requests: retry later")
+	fake.Enqueue(RawUpdate{ID: 1, Payload: json.RawMessage(`{"update_id":1,"message":{"text":"hi"}}`)})
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	lp := telexy.NewLongPoll[telexy.Update](c, telexy.LongPollOptions[telexy.Update]{Decoder: telexy.AsUpdate})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := lp.Stream(ctx)
+
+	// Client.Do retries internally until it gets a response, so the
+	// injected 429 is absorbed there; the streamer only ever sees the
+	// update that was queued behind it.
+	select {
+	case u := <-out:
+		if u.Content.Text != "hi" {
+			t.Fatalf("got %q, want hi", u.Content.Text)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for update after recovery")
+	}
+}
+
+func TestFakeServerHoldsUntilUpdateArrivesOrTimeout(t *testing.T) {
+	fake := NewFakeServer()
+	fake.SetHoldDuration(50 * time.Millisecond)
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	start := time.Now()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("returned after %v, expected to hold for at least the configured duration", elapsed)
+	}
+
+	var body struct {
+		OK     bool              `json:"ok"`
+		Result []json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if !body.OK || len(body.Result) != 0 {
+		t.Fatalf("got %+v, want ok with empty result", body)
+	}
+}
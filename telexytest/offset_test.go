@@ -0,0 +1,93 @@
+package telexytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func getUpdatesAt(t *testing.T, srv *httptest.Server, offset int64) []json.RawMessage {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"offset": offset, "timeout": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		OK     bool              `json:"ok"`
+		Result []json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.OK {
+		t.Fatalf("getUpdates(offset=%d) returned ok=false", offset)
+	}
+	return decoded.Result
+}
+
+func TestFakeServerFiltersByOffset(t *testing.T) {
+	fake := NewFakeServer()
+	fake.Enqueue(
+		RawUpdate{ID: 1, Payload: json.RawMessage(`{"update_id":1}`)},
+		RawUpdate{ID: 2, Payload: json.RawMessage(`{"update_id":2}`)},
+		RawUpdate{ID: 3, Payload: json.RawMessage(`{"update_id":3}`)},
+	)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	if got := getUpdatesAt(t, srv, 0); len(got) != 3 {
+		t.Fatalf("offset=0: got %d updates, want 3", len(got))
+	}
+	if got := getUpdatesAt(t, srv, 2); len(got) != 2 {
+		t.Fatalf("offset=2: got %d updates, want 2", len(got))
+	}
+	if got := getUpdatesAt(t, srv, 4); len(got) != 0 {
+		t.Fatalf("offset=4: got %d updates, want 0", len(got))
+	}
+}
+
+func TestFakeServerTracksAckedIDsAsOffsetAdvances(t *testing.T) {
+	fake := NewFakeServer()
+	fake.Enqueue(
+		RawUpdate{ID: 1, Payload: json.RawMessage(`{"update_id":1}`)},
+		RawUpdate{ID: 2, Payload: json.RawMessage(`{"update_id":2}`)},
+	)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	getUpdatesAt(t, srv, 0)
+	if acked := fake.AckedIDs(); len(acked) != 0 {
+		t.Fatalf("offset=0 should not ack anything yet, got %v", acked)
+	}
+
+	getUpdatesAt(t, srv, 2)
+	if acked := fake.AckedIDs(); len(acked) != 1 || acked[0] != 1 {
+		t.Fatalf("got acked %v, want [1]", acked)
+	}
+
+	getUpdatesAt(t, srv, 3)
+	if acked := fake.AckedIDs(); len(acked) != 2 || acked[1] != 2 {
+		t.Fatalf("got acked %v, want [1 2]", acked)
+	}
+}
+
+func TestFakeServerEnqueueAfterAckIsStillServed(t *testing.T) {
+	fake := NewFakeServer()
+	fake.Enqueue(RawUpdate{ID: 1, Payload: json.RawMessage(`{"update_id":1}`)})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	getUpdatesAt(t, srv, 2) // acks update 1
+
+	fake.Enqueue(RawUpdate{ID: 2, Payload: json.RawMessage(`{"update_id":2}`)})
+	if got := getUpdatesAt(t, srv, 2); len(got) != 1 {
+		t.Fatalf("got %d updates, want 1", len(got))
+	}
+}
@@ -0,0 +1,93 @@
+package telexy
+
+import (
+	"container/list"
+	"context"
+	"time"
+)
+
+// ThrottlePerKey relays values from in to the returned Stream, dropping a
+// value if another value sharing its key (as computed by key) passed
+// through less than rate ago. Pacing is independent per key, so e.g.
+// "at most one update per chat per second" doesn't force a burst from one
+// chat to also throttle every other chat, the way a single global rate
+// limit would.
+//
+// Per-key last-emit timestamps are kept in an LRU bounded by maxKeys, so a
+// long-running stream touching many distinct keys doesn't grow this
+// combinator's memory without bound. A key evicted for being idle simply
+// starts fresh the next time it's seen, same as never having been
+// throttled; maxKeys should be sized comfortably above the number of keys
+// actually active at once, not the total number ever seen. Inject now for
+// deterministic testing. The returned stream closes once ctx is canceled
+// or in closes.
+func ThrottlePerKey[T any](ctx context.Context, in Stream[T], key func(T) string, rate time.Duration, maxKeys int, now func() time.Time) Stream[T] {
+	out := make(chan T)
+	t := &keyThrottle{rate: rate, max: maxKeys, now: now, order: list.New(), index: make(map[string]*list.Element)}
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				if !t.allow(key(v)) {
+					continue
+				}
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// keyThrottle tracks, for each key, when it last let a value through. It's
+// only ever touched from ThrottlePerKey's single driving goroutine, so
+// unlike CallbackDedup (which is shared across callers) it needs no mutex.
+type keyThrottle struct {
+	rate time.Duration
+	max  int
+	now  func() time.Time
+
+	order *list.List
+	index map[string]*list.Element
+}
+
+type keyThrottleEntry struct {
+	key  string
+	last time.Time
+}
+
+// allow reports whether a value keyed by k may pass, and records the
+// decision's timestamp against k either way.
+func (t *keyThrottle) allow(k string) bool {
+	now := t.now()
+
+	if el, ok := t.index[k]; ok {
+		t.order.MoveToFront(el)
+		entry := el.Value.(*keyThrottleEntry)
+		if now.Sub(entry.last) < t.rate {
+			return false
+		}
+		entry.last = now
+		return true
+	}
+
+	el := t.order.PushFront(&keyThrottleEntry{key: k, last: now})
+	t.index[k] = el
+	for t.order.Len() > t.max {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.index, oldest.Value.(*keyThrottleEntry).key)
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package telexy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottlePerKeyPacesKeysIndependently(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var clockNanos atomic.Int64
+	now := func() time.Time { return time.Unix(0, clockNanos.Load()) }
+
+	in := make(chan string)
+	out := ThrottlePerKey(ctx, Stream[string](in), func(v string) string { return v[:1] }, time.Second, 16, now)
+
+	send := func(v string) bool {
+		in <- v
+		select {
+		case got := <-out:
+			return got == v
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}
+	dropped := func(v string) bool {
+		in <- v
+		select {
+		case <-out:
+			return false
+		case <-time.After(50 * time.Millisecond):
+			return true
+		}
+	}
+
+	if !send("a1") {
+		t.Fatal("expected first value for key \"a\" to pass")
+	}
+	if !send("b1") {
+		t.Fatal("expected first value for key \"b\" to pass, independent of key \"a\"")
+	}
+	if !dropped("a2") {
+		t.Fatal("expected second value for key \"a\" within the rate window to be dropped")
+	}
+
+	clockNanos.Add(int64(time.Second))
+	if !send("a3") {
+		t.Fatal("expected value for key \"a\" to pass once rate has elapsed")
+	}
+
+	close(in)
+	if _, ok := <-out; ok {
+		t.Fatal("expected out to close once in closes")
+	}
+}
+
+func TestThrottlePerKeyEvictsIdleKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now
+
+	in := make(chan string)
+	out := ThrottlePerKey(ctx, Stream[string](in), func(v string) string { return v }, time.Minute, 1, now)
+
+	in <- "a"
+	if got := <-out; got != "a" {
+		t.Fatalf("got %q, want a", got)
+	}
+
+	// "b" evicts "a" from the size-1 LRU, so "a" starts fresh even though
+	// rate (a minute) hasn't elapsed.
+	in <- "b"
+	if got := <-out; got != "b" {
+		t.Fatalf("got %q, want b", got)
+	}
+
+	in <- "a"
+	select {
+	case got := <-out:
+		if got != "a" {
+			t.Fatalf("got %q, want a", got)
+		}
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("expected \"a\" to pass again after being evicted from the LRU")
+	}
+
+	close(in)
+}
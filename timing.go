@@ -0,0 +1,18 @@
+package telexy
+
+import "time"
+
+// TimeHandlers calls f for every value received from in, timing each
+// invocation and calling onSlow when it exceeds slow. Unlike a timeout
+// combinator, it never cancels or abandons f; it's purely a diagnostic for
+// finding which handlers are slow in production, without changing
+// behavior. TimeHandlers blocks until in closes.
+func TimeHandlers[T any](in Stream[T], f func(T), slow time.Duration, onSlow func(T, time.Duration)) {
+	for v := range in {
+		start := time.Now()
+		f(v)
+		if elapsed := time.Since(start); elapsed > slow && onSlow != nil {
+			onSlow(v, elapsed)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package telexy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeHandlersReportsSlowInvocations(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		in <- 1
+		in <- 2
+		close(in)
+	}()
+
+	var slowCalls []int
+	TimeHandlers(Stream[int](in), func(v int) {
+		if v == 2 {
+			time.Sleep(30 * time.Millisecond)
+		}
+	}, 10*time.Millisecond, func(v int, elapsed time.Duration) {
+		slowCalls = append(slowCalls, v)
+	})
+
+	if len(slowCalls) != 1 || slowCalls[0] != 2 {
+		t.Fatalf("slowCalls = %v, want [2]", slowCalls)
+	}
+}
+
+func TestTimeHandlersDoesNotFireForFastInvocations(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		in <- 1
+		close(in)
+	}()
+
+	var slowCalls []int
+	TimeHandlers(Stream[int](in), func(v int) {}, 50*time.Millisecond, func(v int, elapsed time.Duration) {
+		slowCalls = append(slowCalls, v)
+	})
+
+	if len(slowCalls) != 0 {
+		t.Fatalf("slowCalls = %v, want none", slowCalls)
+	}
+}
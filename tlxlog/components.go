@@ -0,0 +1,12 @@
+package tlxlog
+
+// Component names tag which subsystem emitted a log line, via
+// Logger.WithValues("component", ...), so operators can filter logs by
+// subsystem instead of relying on message text.
+const (
+	ComponentClient   = "client"
+	ComponentRetry    = "retry"
+	ComponentLongPoll = "longpoll"
+	ComponentMux      = "mux"
+	ComponentWebhook  = "webhook"
+)
@@ -0,0 +1,63 @@
+// Package tlxlog defines the logging interface used throughout telexy, kept
+// separate so callers can plug in their own logging backend.
+package tlxlog
+
+// Logger is the logging interface used throughout telexy. Implementations
+// must be safe for concurrent use.
+type Logger interface {
+	Info(msg string, kv ...any)
+	Error(msg string, kv ...any)
+
+	// WithValues returns a Logger that annotates every subsequent message
+	// with the given key/value pairs, in addition to any already attached.
+	WithValues(kv ...any) Logger
+}
+
+// DebugLogger is an optional extension of Logger for verbose diagnostics
+// that are too noisy to log at Info level, such as every long-poll batch
+// fetched or every mux subscription matched. It's kept as a separate
+// interface rather than a method on Logger itself, since adding a method
+// to Logger would break every existing implementer; reach it through the
+// Debug helper instead of asserting for it directly.
+type DebugLogger interface {
+	Debug(msg string, kv ...any)
+}
+
+// Debug calls l.Debug(msg, kv...) if l implements DebugLogger, and is a
+// no-op otherwise. Use this instead of a direct type assertion so callers
+// don't need to special-case Loggers that don't support Debug.
+func Debug(l Logger, msg string, kv ...any) {
+	if dl, ok := l.(DebugLogger); ok {
+		dl.Debug(msg, kv...)
+	}
+}
+
+// Namer is an optional extension of Logger for tagging every subsequent
+// message with a component name, mirroring WithValues but for the single
+// well-known "name" concept rather than arbitrary key/values. It's kept
+// separate from Logger for the same reason as DebugLogger: adding a method
+// would break every existing implementer; reach it through the WithName
+// helper instead of asserting for it directly.
+type Namer interface {
+	WithName(name string) Logger
+}
+
+// WithName calls l.WithName(name) if l implements Namer, and returns l
+// unchanged otherwise. Use this instead of a direct type assertion so
+// callers don't need to special-case Loggers that don't support naming.
+func WithName(l Logger, name string) Logger {
+	if n, ok := l.(Namer); ok {
+		return n.WithName(name)
+	}
+	return l
+}
+
+// Nop is a Logger that discards everything, used as the default when no
+// Logger is configured.
+var Nop Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)      {}
+func (nopLogger) Error(string, ...any)     {}
+func (nopLogger) WithValues(...any) Logger { return nopLogger{} }
@@ -0,0 +1,57 @@
+package tlxlog
+
+import "testing"
+
+type debugCapableLogger struct {
+	debugs []string
+}
+
+func (l *debugCapableLogger) Info(string, ...any)      {}
+func (l *debugCapableLogger) Error(string, ...any)     {}
+func (l *debugCapableLogger) WithValues(...any) Logger { return l }
+func (l *debugCapableLogger) Debug(msg string, kv ...any) {
+	l.debugs = append(l.debugs, msg)
+}
+
+func TestDebugCallsThroughWhenSupported(t *testing.T) {
+	l := &debugCapableLogger{}
+	Debug(l, "hello")
+
+	if len(l.debugs) != 1 || l.debugs[0] != "hello" {
+		t.Fatalf("debugs = %v, want [hello]", l.debugs)
+	}
+}
+
+func TestDebugIsNoopWhenUnsupported(t *testing.T) {
+	// Nop doesn't implement DebugLogger; Debug must not panic or do
+	// anything observable.
+	Debug(Nop, "hello")
+}
+
+type namerLogger struct {
+	name string
+}
+
+func (l *namerLogger) Info(string, ...any)      {}
+func (l *namerLogger) Error(string, ...any)     {}
+func (l *namerLogger) WithValues(...any) Logger { return l }
+func (l *namerLogger) WithName(name string) Logger {
+	return &namerLogger{name: name}
+}
+
+func TestWithNameCallsThroughWhenSupported(t *testing.T) {
+	l := &namerLogger{}
+	named := WithName(l, "longpoll")
+
+	got, ok := named.(*namerLogger)
+	if !ok || got.name != "longpoll" {
+		t.Fatalf("WithName(l, \"longpoll\") = %+v, want name longpoll", named)
+	}
+}
+
+func TestWithNameFallsBackWhenUnsupported(t *testing.T) {
+	// Nop doesn't implement Namer; WithName must return it unchanged.
+	if got := WithName(Nop, "longpoll"); got != Nop {
+		t.Fatalf("WithName(Nop, ...) = %v, want Nop unchanged", got)
+	}
+}
@@ -0,0 +1,51 @@
+package tlxlog
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// StdTo returns a Logger backed by its own *log.Logger writing to w, rather
+// than the standard library's global logger. Reaching for log.Println
+// directly (as an earlier version of this package did) meant every telexy
+// bot sharing a process fought over the same global log.Logger's prefix and
+// flags; StdTo owns its destination instead, so it can't interfere with the
+// application's own use of the log package.
+func StdTo(w io.Writer) Logger {
+	return &stdLogger{std: log.New(w, "", log.LstdFlags)}
+}
+
+type stdLogger struct {
+	std    *log.Logger
+	values []any
+}
+
+func (l *stdLogger) Info(msg string, kv ...any) {
+	l.std.Println(format("INFO", msg, l.values, kv))
+}
+
+func (l *stdLogger) Error(msg string, kv ...any) {
+	l.std.Println(format("ERROR", msg, l.values, kv))
+}
+
+func (l *stdLogger) WithValues(kv ...any) Logger {
+	return &stdLogger{std: l.std, values: append(append([]any{}, l.values...), kv...)}
+}
+
+// format renders level, msg, and both sets of key/value pairs as a single
+// log line, matching the "msg key=value ..." shape other Logger
+// implementations in this codebase's tests use.
+func format(level, msg string, sets ...[]any) string {
+	var b strings.Builder
+	b.WriteString(level)
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for _, kv := range sets {
+		for i := 0; i+1 < len(kv); i += 2 {
+			fmt.Fprintf(&b, " %v=%v", kv[i], kv[i+1])
+		}
+	}
+	return b.String()
+}
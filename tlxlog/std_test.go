@@ -0,0 +1,60 @@
+package tlxlog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStdToWritesToGivenWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l := StdTo(&buf)
+
+	l.Info("hello", "key", "value")
+
+	out := buf.String()
+	if !strings.Contains(out, "hello") || !strings.Contains(out, "key=value") {
+		t.Fatalf("output %q missing expected message/fields", out)
+	}
+}
+
+func TestStdToWithValuesAccumulates(t *testing.T) {
+	var buf bytes.Buffer
+	l := StdTo(&buf).WithValues("component", "mux").WithValues("request", "1")
+
+	l.Error("boom")
+
+	out := buf.String()
+	if !strings.Contains(out, "component=mux") || !strings.Contains(out, "request=1") {
+		t.Fatalf("output %q missing accumulated values", out)
+	}
+}
+
+func TestStdToDoesNotTouchGlobalLogger(t *testing.T) {
+	var bufA, bufB bytes.Buffer
+	a := StdTo(&bufA)
+	b := StdTo(&bufB)
+
+	a.Info("to a")
+	b.Info("to b")
+
+	if strings.Contains(bufA.String(), "to b") || strings.Contains(bufB.String(), "to a") {
+		t.Fatal("StdTo loggers leaked output into each other's writer")
+	}
+}
+
+func TestStdToIsSafeForConcurrentUse(t *testing.T) {
+	var buf bytes.Buffer
+	l := StdTo(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info("concurrent")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,80 @@
+package telexy
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// recorded is a single log call captured by recordingLogger, along with the
+// component tag attached via WithValues, so tests can assert which
+// subsystem emitted it.
+type recorded struct {
+	component string
+	msg       string
+	kv        []any
+}
+
+// recordingLogger is a tlxlog.Logger that records every call for
+// assertions, shared by the tests of components that accept a Logger.
+type recordingLogger struct {
+	shared    *recordingLoggerState
+	component string
+}
+
+type recordingLoggerState struct {
+	mu      sync.Mutex
+	entries []recorded
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{shared: &recordingLoggerState{}}
+}
+
+func (l *recordingLogger) Info(msg string, kv ...any)  { l.record(msg, kv) }
+func (l *recordingLogger) Error(msg string, kv ...any) { l.record(msg, kv) }
+func (l *recordingLogger) Debug(msg string, kv ...any) { l.record(msg, kv) }
+
+func (l *recordingLogger) record(msg string, kv []any) {
+	l.shared.mu.Lock()
+	defer l.shared.mu.Unlock()
+	l.shared.entries = append(l.shared.entries, recorded{component: l.component, msg: msg, kv: kv})
+}
+
+// WithValues only tracks the "component" key, which is all these tests
+// need to assert on; any other key/value pairs are accepted but ignored.
+func (l *recordingLogger) WithValues(kv ...any) tlxlog.Logger {
+	component := l.component
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] == "component" {
+			component = fmt.Sprint(kv[i+1])
+		}
+	}
+	return &recordingLogger{shared: l.shared, component: component}
+}
+
+func (l *recordingLogger) entriesForComponent(component string) []string {
+	l.shared.mu.Lock()
+	defer l.shared.mu.Unlock()
+	var msgs []string
+	for _, e := range l.shared.entries {
+		if e.component == component {
+			msgs = append(msgs, e.msg)
+		}
+	}
+	return msgs
+}
+
+// kvForMsg returns the key/value pairs attached to the first recorded call
+// matching msg, for asserting on structured fields like a redacted body.
+func (l *recordingLogger) kvForMsg(msg string) []any {
+	l.shared.mu.Lock()
+	defer l.shared.mu.Unlock()
+	for _, e := range l.shared.entries {
+		if e.msg == msg {
+			return e.kv
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package telexy
+
+// Map relays f(v) for every v received from in to the returned Stream, in
+// order, closing it once in closes. It spawns a single goroutine for the
+// lifetime of in; there's no separate context to cancel it with, since it
+// has nothing to wait on other than in itself; closing in (e.g. because the
+// Streamer's own ctx was canceled) stops it.
+func Map[A, B any](in Stream[A], f func(A) B) Stream[B] {
+	out := make(chan B)
+
+	go func() {
+		defer close(out)
+		for v := range in {
+			out <- f(v)
+		}
+	}()
+
+	return out
+}
+
+// Filter relays every v received from in for which pred(v) is true to the
+// returned Stream, in order, closing it once in closes. Like Map, it has no
+// separate context to cancel it with; closing in stops it.
+func Filter[T any](in Stream[T], pred func(T) bool) Stream[T] {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+		for v := range in {
+			if pred(v) {
+				out <- v
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,74 @@
+package telexy
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMapAppliesFunctionInOrder(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	out := Map(Stream[int](in), strconv.Itoa)
+
+	var got []string
+	for v := range out {
+		got = append(got, v)
+	}
+
+	want := []string{"1", "2", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFilterKeepsOnlyMatchingValuesInOrder(t *testing.T) {
+	in := make(chan int, 4)
+	in <- 1
+	in <- 2
+	in <- 3
+	in <- 4
+	close(in)
+
+	out := Filter(Stream[int](in), func(v int) bool { return v%2 == 0 })
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	want := []int{2, 4}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMapClosesOutputWhenInputCloses(t *testing.T) {
+	in := make(chan int)
+	close(in)
+
+	out := Map(Stream[int](in), func(v int) int { return v })
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected output to be immediately closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for output to close")
+	}
+}
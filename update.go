@@ -0,0 +1,26 @@
+package telexy
+
+import "github.com/renbou/telexy/api"
+
+// MessageEntity describes a semantic entity (mention, command, URL, ...)
+// within a message's text, as reported by Telegram in message.entities.
+//
+// It's an alias for api.MessageEntity: the definition lives in api so that
+// mux, which also needs to inspect entities while routing, can depend on
+// it without importing telexy itself (which would create an import cycle,
+// since telexy imports mux for MultiBot and Pipeline).
+type MessageEntity = api.MessageEntity
+
+// Content holds the routing-relevant fields extracted from an update's
+// type-specific payload, regardless of which update type it came from.
+//
+// It's an alias for api.Content; see MessageEntity for why the definition
+// lives in api.
+type Content = api.Content
+
+// Update is the minimal, routing-friendly representation of a Telegram
+// update, produced by decoders such as AsUpdate.
+//
+// It's an alias for api.Update; see MessageEntity for why the definition
+// lives in api.
+type Update = api.Update
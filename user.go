@@ -0,0 +1,36 @@
+package telexy
+
+import (
+	"context"
+)
+
+// User is a minimal representation of a Telegram user or bot, covering the
+// fields telexy itself needs.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// GetMe returns information about the bot itself, caching the result on c
+// so subsequent calls to Username don't need a prior GetMe call threaded
+// through by the caller.
+func (c *Client) GetMe(ctx context.Context) (User, error) {
+	u, err := DoInto[User](ctx, c, "getMe", nil)
+	if err != nil {
+		return u, err
+	}
+	c.self.Store(&u)
+	return u, nil
+}
+
+// Username returns the bot's own username, as last observed by GetMe, or
+// "" if GetMe hasn't been called successfully yet. This is mainly useful
+// for mux.WithBotUsername, which needs the bot's username to reject
+// commands addressed to a different bot (e.g. "/start@otherbot").
+func (c *Client) Username() string {
+	u := c.self.Load()
+	if u == nil {
+		return ""
+	}
+	return u.Username
+}
@@ -0,0 +1,51 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetMeDecodesUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"id":42,"username":"examplebot"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := c.GetMe(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 42 || u.Username != "examplebot" {
+		t.Fatalf("got %+v, want {ID:42 Username:examplebot}", u)
+	}
+}
+
+func TestUsernameIsEmptyBeforeGetMeAndCachedAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{"id":42,"username":"examplebot"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Username(); got != "" {
+		t.Fatalf("Username() before GetMe = %q, want empty", got)
+	}
+
+	if _, err := c.GetMe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Username(); got != "examplebot" {
+		t.Fatalf("Username() after GetMe = %q, want examplebot", got)
+	}
+}
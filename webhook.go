@@ -0,0 +1,283 @@
+package telexy
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTelegramIPRanges are the CIDR ranges Telegram's Bot API sends
+// webhook requests from. Pass these to WebhookOptions.AllowedIPRanges to
+// restrict delivery to Telegram itself; a local Bot API server needs its
+// own ranges instead.
+var DefaultTelegramIPRanges = []string{"149.154.160.0/20", "91.108.4.0/22"}
+
+// WebhookOptions configures a webhook Streamer.
+type WebhookOptions struct {
+	// SecretToken, if set, must match the X-Telegram-Bot-Api-Secret-Token
+	// header Telegram sends on every request once configured via
+	// setWebhook's secret_token parameter. A request with a missing or
+	// mismatched header is rejected with 401 Unauthorized and never
+	// reaches the decoder. Left empty, every request is accepted.
+	SecretToken string
+
+	// ShutdownTimeout bounds how long Stream waits for in-flight requests
+	// to finish once ctx is canceled, via http.Server.Shutdown. Defaults
+	// to 5 seconds.
+	ShutdownTimeout time.Duration
+
+	// AckDeadline, if positive, bounds how long a request waits for its
+	// decoded update to be picked up off the stream before giving up and
+	// replying 503 Service Unavailable, which causes Telegram to retry
+	// the delivery later. Left zero, a request blocks until ctx is
+	// canceled (i.e. until Stream shuts down), matching a consumer that
+	// is always ready.
+	AckDeadline time.Duration
+
+	// OnMissedDeadline, if set, is called whenever AckDeadline elapses
+	// before an update was picked up, letting operators track how often
+	// the downstream pipeline is too slow for the webhook delivery SLA.
+	// Called in its own goroutine, so it must not block.
+	OnMissedDeadline func()
+
+	// AllowedIPRanges, if non-empty, restricts accepted webhook requests
+	// to these CIDR ranges (e.g. DefaultTelegramIPRanges), rejecting
+	// anything else with 403 Forbidden before the body is even read.
+	// This is defense-in-depth on top of SecretToken, guarding against a
+	// request that somehow carries the right secret but didn't actually
+	// come from Telegram. Left empty, no IP check is performed. A local
+	// Bot API server sends from its own host, not Telegram's ranges, so
+	// override this accordingly when pointed at one.
+	AllowedIPRanges []string
+
+	// RemoteAddrHeader, if set, reads the client's address from this
+	// header instead of http.Request.RemoteAddr, for deployments behind
+	// a reverse proxy that forwards the original address (e.g.
+	// "X-Forwarded-For"). Has no effect unless AllowedIPRanges is set.
+	// Only trust this behind a proxy that itself sets or overwrites the
+	// header for external clients; otherwise a request can claim
+	// whatever address it likes.
+	RemoteAddrHeader string
+}
+
+type webhookStreamer[T any] struct {
+	addr    string
+	decoder UpdateDecoder[T]
+	opts    WebhookOptions
+
+	bound     chan struct{}
+	boundOnce sync.Once
+	boundAddr string
+
+	// allowedNets is opts.AllowedIPRanges parsed once by Stream, before
+	// the server starts accepting requests; handlers only ever read it
+	// afterward, so it needs no locking.
+	allowedNets []*net.IPNet
+}
+
+// NewWebhookStreamer creates a Streamer that receives updates via an
+// http.Server listening on addr, as an alternative to polling getUpdates.
+// Each request body is decoded with decoder, using the same
+// jsoniter.Iterator pooling as readResponse, and acknowledged with 200 OK
+// once the decoded update has been handed off; a body that fails to decode
+// gets 400 Bad Request and is dropped.
+//
+// Stream starts listening on addr immediately and reports any bind failure
+// on the returned error channel. Once ctx is canceled, the server is shut
+// down gracefully (bounded by opts.ShutdownTimeout) and a nil error is
+// pushed to the error channel before both channels close, matching the
+// Streamer contract.
+//
+// If opts.AckDeadline is set, a request whose update isn't picked up off
+// the stream within that deadline gets 503 instead of blocking, so a slow
+// downstream consumer surfaces as failed deliveries rather than piling up
+// stuck goroutines.
+func NewWebhookStreamer[T any](addr string, decoder UpdateDecoder[T], opts *WebhookOptions) Streamer[T] {
+	o := WebhookOptions{ShutdownTimeout: 5 * time.Second}
+	if opts != nil {
+		o = *opts
+		if o.ShutdownTimeout <= 0 {
+			o.ShutdownTimeout = 5 * time.Second
+		}
+	}
+	return &webhookStreamer[T]{addr: addr, decoder: decoder, opts: o, bound: make(chan struct{})}
+}
+
+// Addr blocks until Stream has bound its listener and returns the actual
+// address, which is useful when addr passed to NewWebhookStreamer uses port
+// 0 for an ephemeral port (as tests do). It returns "" if the listener
+// never bound (e.g. Stream wasn't called, or binding failed).
+func (s *webhookStreamer[T]) Addr() string {
+	<-s.bound
+	return s.boundAddr
+}
+
+func (s *webhookStreamer[T]) Stream(ctx context.Context) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errc := make(chan error, 1)
+
+	allowedNets, err := parseIPRanges(s.opts.AllowedIPRanges)
+	if err != nil {
+		s.boundOnce.Do(func() { close(s.bound) })
+		close(out)
+		errc <- err
+		close(errc)
+		return out, errc
+	}
+	s.allowedNets = allowedNets
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		s.boundOnce.Do(func() { close(s.bound) })
+		close(out)
+		errc <- err
+		close(errc)
+		return out, errc
+	}
+	s.boundAddr = ln.Addr().String()
+	s.boundOnce.Do(func() { close(s.bound) })
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/", s.handleUpdate(ctx, out))
+	srv := &http.Server{Handler: handler}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		defer close(errc)
+
+		select {
+		case err := <-serveErr:
+			errc <- err
+			return
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.opts.ShutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+		errc <- nil
+	}()
+
+	return out, errc
+}
+
+func (s *webhookStreamer[T]) handleUpdate(ctx context.Context, out chan<- T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.sourceAllowed(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if s.opts.SecretToken != "" {
+			header := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+			if subtle.ConstantTimeCompare([]byte(header), []byte(s.opts.SecretToken)) != 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		it := json.BorrowIterator(data)
+		u, err := s.decoder(it)
+		json.ReturnIterator(it)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		sendCtx := ctx
+		if s.opts.AckDeadline > 0 {
+			var cancel context.CancelFunc
+			sendCtx, cancel = context.WithTimeout(ctx, s.opts.AckDeadline)
+			defer cancel()
+		}
+
+		select {
+		case out <- u:
+			w.WriteHeader(http.StatusOK)
+		case <-sendCtx.Done():
+			if sendCtx.Err() == context.DeadlineExceeded {
+				s.onMissedDeadline()
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+}
+
+func (s *webhookStreamer[T]) onMissedDeadline() {
+	if s.opts.OnMissedDeadline != nil {
+		go s.opts.OnMissedDeadline()
+	}
+}
+
+// parseIPRanges parses ranges as CIDRs, for validating against once per
+// request. A nil/empty ranges is valid and means "no IP check".
+func parseIPRanges(ranges []string) ([]*net.IPNet, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+	nets := make([]*net.IPNet, 0, len(ranges))
+	for _, r := range ranges {
+		_, n, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, fmt.Errorf("telexy: invalid webhook IP range %q: %w", r, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// sourceIP extracts the address to check against s.allowedNets: either
+// opts.RemoteAddrHeader's value (taking the first entry if it's a
+// comma-separated list, as X-Forwarded-For is when a request passed
+// through several proxies) or r.RemoteAddr with its port stripped.
+func (s *webhookStreamer[T]) sourceIP(r *http.Request) string {
+	if s.opts.RemoteAddrHeader != "" {
+		v := r.Header.Get(s.opts.RemoteAddrHeader)
+		if i := strings.IndexByte(v, ','); i != -1 {
+			v = v[:i]
+		}
+		return strings.TrimSpace(v)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// sourceAllowed reports whether r's source address falls within one of
+// s.allowedNets. It's always true when AllowedIPRanges wasn't set.
+func (s *webhookStreamer[T]) sourceAllowed(r *http.Request) bool {
+	if len(s.allowedNets) == 0 {
+		return true
+	}
+	ip := net.ParseIP(s.sourceIP(r))
+	if ip == nil {
+		return false
+	}
+	for _, n := range s.allowedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
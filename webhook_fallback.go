@@ -0,0 +1,239 @@
+package telexy
+
+import (
+	"context"
+	"time"
+
+	"github.com/renbou/telexy/tlxlog"
+)
+
+// WebhookFallbackOptions configures NewWebhookWithFallback's policy for
+// deciding a webhook has gone unreachable and switching to long polling.
+type WebhookFallbackOptions struct {
+	// IdleTimeout is how long the streamer waits without receiving any
+	// update over the webhook before it starts checking getWebhookInfo at
+	// all. This keeps a bot that's simply quiet (no chat activity) from
+	// being mistaken for one whose webhook is broken. Defaults to
+	// DefaultWebhookFallbackIdleTimeout.
+	IdleTimeout time.Duration
+
+	// CheckInterval is how often getWebhookInfo is polled once
+	// IdleTimeout has elapsed with no updates. Defaults to
+	// DefaultWebhookFallbackCheckInterval.
+	CheckInterval time.Duration
+
+	// MaxErrorAge bounds how recent WebhookInfo.LastErrorDate must be for
+	// it to count as evidence the webhook is currently broken, rather
+	// than a one-off failure from the past that Telegram hasn't cleared
+	// yet. Defaults to DefaultWebhookFallbackMaxErrorAge.
+	MaxErrorAge time.Duration
+
+	// DropPendingUpdates is passed to DeleteWebhook when falling back, so
+	// the bot can choose whether updates queued since the webhook's last
+	// successful delivery should be redelivered via the subsequent
+	// getUpdates calls (false) or discarded (true).
+	DropPendingUpdates bool
+
+	// Logger, if set, receives transition logs, tagged with component
+	// "webhook". Defaults to tlxlog.Nop.
+	Logger tlxlog.Logger
+
+	// now is overridable in tests; defaults to time.Now.
+	now func() time.Time
+}
+
+// DefaultWebhookFallbackIdleTimeout is the default
+// WebhookFallbackOptions.IdleTimeout.
+const DefaultWebhookFallbackIdleTimeout = 2 * time.Minute
+
+// DefaultWebhookFallbackCheckInterval is the default
+// WebhookFallbackOptions.CheckInterval.
+const DefaultWebhookFallbackCheckInterval = 30 * time.Second
+
+// DefaultWebhookFallbackMaxErrorAge is the default
+// WebhookFallbackOptions.MaxErrorAge.
+const DefaultWebhookFallbackMaxErrorAge = 5 * time.Minute
+
+func (o *WebhookFallbackOptions) withDefaults() WebhookFallbackOptions {
+	out := WebhookFallbackOptions{}
+	if o != nil {
+		out = *o
+	}
+	if out.IdleTimeout <= 0 {
+		out.IdleTimeout = DefaultWebhookFallbackIdleTimeout
+	}
+	if out.CheckInterval <= 0 {
+		out.CheckInterval = DefaultWebhookFallbackCheckInterval
+	}
+	if out.MaxErrorAge <= 0 {
+		out.MaxErrorAge = DefaultWebhookFallbackMaxErrorAge
+	}
+	if out.Logger == nil {
+		out.Logger = tlxlog.Nop
+	}
+	if out.now == nil {
+		out.now = time.Now
+	}
+	return out
+}
+
+type webhookFallbackStreamer[T any] struct {
+	client       *Client
+	webhook      *webhookStreamer[T]
+	longPoll     Streamer[T]
+	fallbackOpts WebhookFallbackOptions
+	logger       tlxlog.Logger
+}
+
+// NewWebhookWithFallback creates a Streamer that primarily receives updates
+// via a webhook, but degrades to long polling if the webhook appears to
+// have stopped working: no update has arrived for fallbackOpts.IdleTimeout
+// and a getWebhookInfo check confirms Telegram itself is failing to
+// deliver (WebhookInfo.LastErrorDate is recent) or can't be reached at all.
+// Once triggered, it calls DeleteWebhook and switches to a long-poll
+// Streamer built from longPollOpts; it never switches back, since recovery
+// of whatever broke the webhook (a cert renewal, a port fix) is expected to
+// require operator intervention anyway, after which the bot would normally
+// be restarted with setWebhook reconfigured.
+//
+// addr and decoder are passed straight through to NewWebhookStreamer, along
+// with webhookOpts (which may be nil). client is used both for the webhook
+// health checks (GetWebhookInfo, DeleteWebhook) and, by NewLongPoll, for the
+// fallback polling itself.
+func NewWebhookWithFallback[T any](client *Client, addr string, decoder UpdateDecoder[T], webhookOpts *WebhookOptions, longPollOpts LongPollOptions[T], fallbackOpts *WebhookFallbackOptions) Streamer[T] {
+	opts := fallbackOpts.withDefaults()
+	webhook := NewWebhookStreamer[T](addr, decoder, webhookOpts).(*webhookStreamer[T])
+	return &webhookFallbackStreamer[T]{
+		client:       client,
+		webhook:      webhook,
+		longPoll:     NewLongPoll[T](client, longPollOpts),
+		fallbackOpts: opts,
+		logger:       opts.Logger.WithValues("component", tlxlog.ComponentWebhook),
+	}
+}
+
+// Addr blocks until the webhook listener has bound and returns its
+// address; see webhookStreamer.Addr.
+func (s *webhookFallbackStreamer[T]) Addr() string {
+	return s.webhook.Addr()
+}
+
+func (s *webhookFallbackStreamer[T]) Stream(ctx context.Context) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errc := make(chan error, 1)
+
+	go s.run(ctx, out, errc)
+
+	return out, errc
+}
+
+func (s *webhookFallbackStreamer[T]) run(ctx context.Context, out chan<- T, errc chan<- error) {
+	defer close(out)
+	defer close(errc)
+
+	whOut, whErrc := s.webhook.Stream(ctx)
+
+	ticker := time.NewTicker(s.fallbackOpts.CheckInterval)
+	defer ticker.Stop()
+
+	lastUpdate := s.fallbackOpts.now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case err, ok := <-whErrc:
+			if !ok {
+				whErrc = nil
+				continue
+			}
+			if err != nil {
+				select {
+				case errc <- err:
+				default:
+				}
+			}
+
+		case u, ok := <-whOut:
+			if !ok {
+				// The webhook streamer shut down on its own (e.g. ctx was
+				// canceled, which the outer select will also observe); just
+				// stop relaying from it.
+				whOut = nil
+				continue
+			}
+			lastUpdate = s.fallbackOpts.now()
+			select {
+			case out <- u:
+			case <-ctx.Done():
+				return
+			}
+
+		case <-ticker.C:
+			if s.fallbackOpts.now().Sub(lastUpdate) < s.fallbackOpts.IdleTimeout {
+				continue
+			}
+			if !s.webhookLooksBroken(ctx) {
+				continue
+			}
+			s.fallBackToLongPoll(ctx, out, errc)
+			return
+		}
+	}
+}
+
+// webhookLooksBroken checks getWebhookInfo for evidence Telegram itself is
+// failing to deliver to the webhook, rather than the bot simply being idle.
+func (s *webhookFallbackStreamer[T]) webhookLooksBroken(ctx context.Context) bool {
+	info, err := s.client.GetWebhookInfo(ctx)
+	if err != nil {
+		s.logger.Error("getWebhookInfo failed while checking webhook health", "err", err)
+		return true
+	}
+	if info.LastErrorMessage == "" {
+		return false
+	}
+	errAge := s.fallbackOpts.now().Sub(time.Unix(info.LastErrorDate, 0))
+	if errAge > s.fallbackOpts.MaxErrorAge {
+		return false
+	}
+	s.logger.Error("webhook reported a recent delivery error", "err", info.LastErrorMessage, "age", errAge)
+	return true
+}
+
+// fallBackToLongPoll deletes the webhook and relays from a long-poll
+// Streamer for the rest of ctx's lifetime.
+func (s *webhookFallbackStreamer[T]) fallBackToLongPoll(ctx context.Context, out chan<- T, errc chan<- error) {
+	s.logger.Info("falling back from webhook to long polling")
+
+	if err := s.client.DeleteWebhook(ctx, s.fallbackOpts.DropPendingUpdates); err != nil {
+		s.logger.Error("deleteWebhook failed while falling back to long polling", "err", err)
+	}
+
+	lpOut, lpErrc := s.longPoll.Stream(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-lpErrc:
+			if !ok {
+				lpErrc = nil
+				continue
+			}
+			select {
+			case errc <- err:
+			default:
+			}
+		case u, ok := <-lpOut:
+			if !ok {
+				return
+			}
+			select {
+			case out <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package telexy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookWithFallbackDeliversUpdatesViaWebhookWhenHealthy(t *testing.T) {
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected API call to %s; a healthy webhook should never need one", r.URL.Path)
+	}))
+	defer apiSrv.Close()
+
+	c, err := NewClient(apiSrv.URL, "tok", apiSrv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws := NewWebhookWithFallback[Update](c, "127.0.0.1:0", AsUpdate, nil, LongPollOptions[Update]{Decoder: AsUpdate}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, _ := ws.Stream(ctx)
+
+	addr := ws.(*webhookFallbackStreamer[Update]).Addr()
+	body := []byte(`{"update_id":1,"message":{"text":"hi"}}`)
+	resp, err := http.Post("http://"+addr+"/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+
+	select {
+	case u := <-out:
+		if u.Content.Text != "hi" {
+			t.Fatalf("unexpected update: %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update via webhook")
+	}
+}
+
+func TestWebhookWithFallbackSwitchesToLongPollWhenWebhookIsBroken(t *testing.T) {
+	var deleteWebhookCalled bool
+	var pollCount int32
+
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getWebhookInfo"):
+			w.Write([]byte(`{"ok":true,"result":{
+				"url":"https://example.com/hook",
+				"pending_update_count":0,
+				"last_error_date":1700000000,
+				"last_error_message":"connection refused"
+			}}`))
+		case strings.HasSuffix(r.URL.Path, "/deleteWebhook"):
+			deleteWebhookCalled = true
+			w.Write([]byte(`{"ok":true,"result":true}`))
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			if pollCount == 0 {
+				pollCount++
+				w.Write([]byte(`{"ok":true,"result":[{"update_id":9,"message":{"text":"via long poll"}}]}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"result":[]}`))
+		default:
+			t.Fatalf("unexpected API call to %s", r.URL.Path)
+		}
+	}))
+	defer apiSrv.Close()
+
+	c, err := NewClient(apiSrv.URL, "tok", apiSrv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Unix(1700000000, 0)
+	var clockCalls int
+	fakeNow := func() time.Time {
+		clockCalls++
+		return base.Add(time.Duration(clockCalls) * time.Hour)
+	}
+
+	fallbackOpts := &WebhookFallbackOptions{
+		IdleTimeout:   time.Second,
+		CheckInterval: 10 * time.Millisecond,
+		MaxErrorAge:   24 * time.Hour,
+		now:           fakeNow,
+	}
+
+	ws := NewWebhookWithFallback[Update](c, "127.0.0.1:0", AsUpdate, nil, LongPollOptions[Update]{Decoder: AsUpdate}, fallbackOpts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, _ := ws.Stream(ctx)
+
+	select {
+	case u := <-out:
+		if u.Content.Text != "via long poll" {
+			t.Fatalf("unexpected update: %+v", u)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for fallback delivery via long polling")
+	}
+
+	if !deleteWebhookCalled {
+		t.Fatal("expected deleteWebhook to be called when falling back")
+	}
+}
@@ -0,0 +1,35 @@
+package telexy
+
+import "context"
+
+// WebhookInfo mirrors Telegram's getWebhookInfo result, covering the fields
+// telexy needs to tell a healthy webhook from one that's stopped receiving
+// deliveries.
+type WebhookInfo struct {
+	URL                          string `json:"url"`
+	HasCustomCertificate         bool   `json:"has_custom_certificate,omitempty"`
+	PendingUpdateCount           int    `json:"pending_update_count"`
+	LastErrorDate                int64  `json:"last_error_date,omitempty"`
+	LastErrorMessage             string `json:"last_error_message,omitempty"`
+	LastSynchronizationErrorDate int64  `json:"last_synchronization_error_date,omitempty"`
+	MaxConnections               int    `json:"max_connections,omitempty"`
+}
+
+// GetWebhookInfo returns the bot's current webhook configuration and
+// delivery status, including Telegram's own record of the last delivery
+// error, if any.
+func (c *Client) GetWebhookInfo(ctx context.Context) (WebhookInfo, error) {
+	return DoInto[WebhookInfo](ctx, c, "getWebhookInfo", nil)
+}
+
+// DeleteWebhook removes the bot's current webhook, after which Telegram
+// queues updates for retrieval via getUpdates instead of pushing them.
+// dropPendingUpdates discards any updates queued since the webhook last
+// delivered successfully; otherwise they're delivered to the first
+// subsequent getUpdates call.
+func (c *Client) DeleteWebhook(ctx context.Context, dropPendingUpdates bool) error {
+	_, err := DoInto[bool](ctx, c, "deleteWebhook", struct {
+		DropPendingUpdates bool `json:"drop_pending_updates"`
+	}{dropPendingUpdates})
+	return err
+}
@@ -0,0 +1,57 @@
+package telexy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetWebhookInfoDecodesLastError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"result":{
+			"url":"https://example.com/hook",
+			"pending_update_count":3,
+			"last_error_date":1700000000,
+			"last_error_message":"connection refused"
+		}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := c.GetWebhookInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.URL != "https://example.com/hook" || info.PendingUpdateCount != 3 {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.LastErrorDate != 1700000000 || info.LastErrorMessage != "connection refused" {
+		t.Fatalf("expected the last error to be decoded, got %+v", info)
+	}
+}
+
+func TestDeleteWebhookSendsDropPendingUpdates(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tok", srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.DeleteWebhook(context.Background(), true); err != nil {
+		t.Fatal(err)
+	}
+	if gotBody["drop_pending_updates"] != true {
+		t.Fatalf("got body %v, want drop_pending_updates=true", gotBody)
+	}
+}
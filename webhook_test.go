@@ -0,0 +1,228 @@
+package telexy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWebhookStreamerDecodesPostedUpdates(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, errc := ws.Stream(ctx)
+
+	addr := ws.(*webhookStreamer[Update]).Addr()
+	if addr == "" {
+		t.Fatal("expected the webhook streamer to bind a listener")
+	}
+
+	body := []byte(`{"update_id":1,"message":{"text":"hi","chat":{"id":5}}}`)
+	resp, err := http.Post("http://"+addr+"/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+
+	select {
+	case u := <-out:
+		if u.Content.Text != "hi" || u.Content.ChatID != 5 {
+			t.Fatalf("unexpected update: %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+
+	cancel()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("expected nil error after cancellation, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for streamer to shut down")
+	}
+}
+
+func TestWebhookStreamerRejectsWrongSecretToken(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, &WebhookOptions{SecretToken: "shh"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, _ = ws.Stream(ctx)
+	addr := ws.(*webhookStreamer[Update]).Addr()
+
+	body := []byte(`{"update_id":1,"message":{"text":"hi"}}`)
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr+"/", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", "wrong")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestWebhookStreamerRepliesServiceUnavailableWhenAckDeadlineElapses(t *testing.T) {
+	missed := make(chan struct{}, 1)
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, &WebhookOptions{
+		AckDeadline:      20 * time.Millisecond,
+		OnMissedDeadline: func() { missed <- struct{}{} },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, _ := ws.Stream(ctx)
+	addr := ws.(*webhookStreamer[Update]).Addr()
+
+	// Nothing ever reads from out, so the update can never be picked up
+	// before the deadline elapses.
+	_ = out
+
+	body := []byte(`{"update_id":1,"message":{"text":"hi"}}`)
+	resp, err := http.Post("http://"+addr+"/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want 503", resp.StatusCode)
+	}
+
+	select {
+	case <-missed:
+	case <-time.After(time.Second):
+		t.Fatal("expected OnMissedDeadline to fire")
+	}
+}
+
+func TestWebhookStreamerRejectsMalformedBody(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, _ = ws.Stream(ctx)
+	addr := ws.(*webhookStreamer[Update]).Addr()
+
+	resp, err := http.Post("http://"+addr+"/", "application/json", bytes.NewReader([]byte("not json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestWebhookStreamerAcceptsRequestsFromAnAllowedIPRange(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, &WebhookOptions{
+		AllowedIPRanges: []string{"127.0.0.0/8"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, _ := ws.Stream(ctx)
+	addr := ws.(*webhookStreamer[Update]).Addr()
+
+	body := []byte(`{"update_id":1,"message":{"text":"hi"}}`)
+	resp, err := http.Post("http://"+addr+"/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+
+	select {
+	case <-out:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
+
+func TestWebhookStreamerRejectsRequestsOutsideAllowedIPRanges(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, &WebhookOptions{
+		AllowedIPRanges: DefaultTelegramIPRanges,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, _ = ws.Stream(ctx)
+	addr := ws.(*webhookStreamer[Update]).Addr()
+
+	body := []byte(`{"update_id":1,"message":{"text":"hi"}}`)
+	resp, err := http.Post("http://"+addr+"/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("got status %d, want 403 (127.0.0.1 isn't in Telegram's ranges)", resp.StatusCode)
+	}
+}
+
+func TestWebhookStreamerUsesRemoteAddrHeaderWhenConfigured(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, &WebhookOptions{
+		AllowedIPRanges:  []string{"149.154.160.0/20"},
+		RemoteAddrHeader: "X-Forwarded-For",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, _ := ws.Stream(ctx)
+	addr := ws.(*webhookStreamer[Update]).Addr()
+
+	body := []byte(`{"update_id":1,"message":{"text":"hi"}}`)
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr+"/", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Forwarded-For", "149.154.167.50, 10.0.0.1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200 (header's first address is in range, despite the real peer being localhost)", resp.StatusCode)
+	}
+
+	select {
+	case <-out:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
+
+func TestNewWebhookStreamerReportsInvalidIPRangeOnStream(t *testing.T) {
+	ws := NewWebhookStreamer[Update]("127.0.0.1:0", AsUpdate, &WebhookOptions{
+		AllowedIPRanges: []string{"not-a-cidr"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, errc := ws.Stream(ctx)
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("expected an error for an invalid CIDR")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the invalid-range error")
+	}
+}